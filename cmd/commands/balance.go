@@ -16,25 +16,35 @@ package commands
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
+	"strings"
+	"time"
 
+	"github.com/sboehler/knut/cmd/diagnostics"
 	"github.com/sboehler/knut/cmd/flags"
 	"github.com/sboehler/knut/lib/amounts"
+	"github.com/sboehler/knut/lib/common/date"
 	"github.com/sboehler/knut/lib/common/mapper"
 	"github.com/sboehler/knut/lib/common/predicate"
+	"github.com/sboehler/knut/lib/common/set"
 	"github.com/sboehler/knut/lib/common/table"
 	"github.com/sboehler/knut/lib/journal"
 	"github.com/sboehler/knut/lib/journal/check"
 	"github.com/sboehler/knut/lib/model"
 	"github.com/sboehler/knut/lib/model/account"
 	"github.com/sboehler/knut/lib/model/commodity"
+	"github.com/sboehler/knut/lib/model/price"
 	"github.com/sboehler/knut/lib/model/registry"
 	"github.com/sboehler/knut/lib/reports/balance"
+	"github.com/shopspring/decimal"
 
+	"github.com/natefinch/atomic"
 	"github.com/spf13/cobra"
 )
 
@@ -62,8 +72,12 @@ type balanceRunner struct {
 	cpuprofile string
 
 	// journal structure
-	close     bool
-	valuation flags.CommodityFlag
+	close            bool
+	valuation        flags.CommodityFlag
+	ignoreAssertions bool
+	valuationDate    string
+	dropUnvalued     bool
+	showRates        bool
 
 	// mapping
 	mapping flags.MappingFlag
@@ -77,12 +91,23 @@ type balanceRunner struct {
 	diff               bool
 	showCommodities    flags.RegexFlag
 	sortAlphabetically bool
+	foldBelow          float64
+	showZeroDiff       bool
+	byCommodity        bool
 
 	// formatting
-	thousands bool
-	color     bool
-	digits    int32
-	csv       bool
+	thousands    bool
+	color        bool
+	digits       int32
+	rounding     string
+	csv          bool
+	border       bool
+	sparkline    bool
+	periodLabels string
+
+	// output
+	splitByPeriod bool
+	outDir        string
 }
 
 func (r *balanceRunner) run(cmd *cobra.Command, args []string) {
@@ -105,36 +130,92 @@ func (r *balanceRunner) setupFlags(c *cobra.Command) {
 	r.Multiperiod.Setup(c)
 	c.Flags().StringVar(&r.cpuprofile, "cpuprofile", "", "file to write profile")
 	c.Flags().BoolVarP(&r.diff, "diff", "d", false, "diff")
+	c.Flags().BoolVar(&r.showZeroDiff, "show-zero-diff", false, "in diff mode, show zero for periods without movements")
 	c.Flags().BoolVarP(&r.csv, "csv", "", false, "csv")
+	c.Flags().BoolVar(&r.splitByPeriod, "split-by-period", false, "write one file per period to the output directory")
+	c.Flags().StringVar(&r.outDir, "out-dir", ".", "output directory for --split-by-period")
 	c.Flags().BoolVar(&r.close, "close", true, "close")
+	c.Flags().BoolVar(&r.ignoreAssertions, "ignore-assertions", false, "do not check assertions")
+	c.Flags().BoolVar(&r.byCommodity, "by-commodity", false, "group asset and liability accounts by commodity, with a total per commodity")
 	c.Flags().BoolVarP(&r.sortAlphabetically, "sort", "a", false, "Sort accounts alphabetically")
 	c.Flags().VarP(&r.showCommodities, "show-commodities", "s", "<regex>")
+	c.Flags().Float64Var(&r.foldBelow, "fold-below", 0, "fold commodity rows with a valued amount below the threshold into one row")
 	c.Flags().VarP(&r.valuation, "val", "v", "valuate in the given commodity")
+	c.Flags().BoolVar(&r.dropUnvalued, "drop-unvalued", false, "value commodities without a price at zero instead of showing them as unvalued")
+	c.Flags().BoolVar(&r.showRates, "show-rates", false, "show the rates used to valuate each commodity, requires a valuation commodity")
+	c.Flags().StringVar(&r.valuationDate, "valuation-date", "end", "value the positions of a period at the prices of its start or end")
 	c.Flags().VarP(&r.mapping, "map", "m", "<level>,<regex>")
 	c.Flags().VarP(&r.remap, "remap", "r", "<regex>")
 	c.Flags().Var(&r.accounts, "account", "filter accounts with a regex")
 	c.Flags().Var(&r.commodities, "commodity", "filter commodities with a regex")
 	c.Flags().Int32Var(&r.digits, "digits", 0, "round to number of digits")
+	c.Flags().StringVar(&r.rounding, "rounding", "half-up", "rounding mode for --digits: half-up, half-even or down")
 	c.Flags().BoolVarP(&r.thousands, "thousands", "k", false, "show numbers in units of 1000")
 	c.Flags().BoolVar(&r.color, "color", true, "print output in color")
+	c.Flags().BoolVar(&r.border, "border", false, "draw the table using box-drawing characters")
+	c.Flags().BoolVar(&r.sparkline, "sparkline", false, "show a trend column in the text output")
+	c.Flags().StringVar(&r.periodLabels, "period-labels", "", "format of the period headers: month, quarter, year or a Go time layout")
 }
 
 func (r balanceRunner) execute(cmd *cobra.Command, args []string) error {
 	reg := registry.New()
-	valuation, err := r.valuation.Value(reg)
+	j, err := journal.FromPath(cmd.Context(), reg, args[0])
 	if err != nil {
 		return err
 	}
-	j, err := journal.FromPath(cmd.Context(), reg, args[0])
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	valuation, err := r.valuation.ValueOrDefault(reg)
 	if err != nil {
 		return err
 	}
-	partition := r.Multiperiod.Partition(j.Period())
+	if p, ok := reg.Options().Precision(); ok && !cmd.Flags().Changed("digits") {
+		r.digits = p
+	}
+	rounding, err := table.ParseRounding(r.rounding)
+	if err != nil {
+		return err
+	}
+	if r.valuationDate != "start" && r.valuationDate != "end" {
+		return fmt.Errorf("invalid valuation date %q, want start or end", r.valuationDate)
+	}
+	if r.showRates && valuation == nil {
+		return fmt.Errorf("--show-rates requires a valuation commodity")
+	}
+	partition := r.Multiperiod.Partition(reg, j.Period())
 	report := balance.NewReport(reg, partition)
+	var addRates func(time.Time, price.NormalizedPrices)
+	if valuation != nil {
+		addRates = report.AddRates
+	}
+	checker := check.Checker{NoCheck: r.ignoreAssertions}
+	unvalued := set.New[*model.Commodity]()
+	var collectUnvalued *journal.Processor
+	if valuation != nil && !r.dropUnvalued {
+		report.Unvalued = make(balance.Unvalued)
+		collectUnvalued = journal.Query{
+			Select: amounts.KeyMapper{
+				Date: partition.Align(),
+				Account: mapper.Sequence(
+					account.Remap(reg.Accounts(), r.remap.Regex()),
+					account.Shorten(reg.Accounts(), r.mapping.Value()),
+				),
+				Commodity: mapper.Identity[*model.Commodity],
+			}.Build(),
+			Where: predicate.And(
+				func(k amounts.Key) bool { return k.Account.IsAL() && k.Commodity != valuation },
+				amounts.AccountMatches(r.accounts.Regex()),
+				amounts.CommodityMatches(r.commodities.Regex()),
+			),
+		}.Into(report.Unvalued)
+	}
 	procs := []*journal.Processor{
-		check.Check(),
+		checker.Check(),
+		report.TrackAccounts(),
+		journal.ApplySplits(reg),
 		journal.ComputePrices(valuation),
-		journal.Valuate(reg, valuation),
+		journal.FreezePrices(j, partition, valuation != nil && r.valuationDate == "start"),
+		journal.RecordPrices(j, partition, addRates),
+		journal.ValuateOrSkip(reg, valuation, unvalued.Add),
 		journal.Filter(partition),
 		journal.CloseAccounts(j, reg, r.close, partition),
 		journal.Query{
@@ -153,16 +234,33 @@ func (r balanceRunner) execute(cmd *cobra.Command, args []string) error {
 			),
 			Valuation: valuation,
 		}.Into(report),
+		collectUnvalued,
 	}
 	err = j.Build().Process(procs...)
 	if err != nil {
 		return err
 	}
+	if len(unvalued) > 0 {
+		var names []string
+		for _, c := range unvalued.Sorted(commodity.Compare) {
+			names = append(names, c.Name())
+		}
+		if r.dropUnvalued {
+			diagnostics.Warnf(cmd, "no price found for %s, valued at zero", strings.Join(names, ", "))
+		} else {
+			diagnostics.Warnf(cmd, "no price found for %s, shown as unvalued", strings.Join(names, ", "))
+		}
+	}
 	reportRenderer := balance.Renderer{
 		Valuation:          valuation,
 		CommodityDetails:   r.showCommodities.Regex(),
 		SortAlphabetically: r.sortAlphabetically,
 		Diff:               r.diff,
+		PeriodLabels:       r.periodLabels,
+		FoldBelow:          decimal.NewFromFloat(r.foldBelow),
+		ShowZeroDiff:       r.showZeroDiff,
+		ByCommodity:        r.byCommodity,
+		ShowRates:          r.showRates,
 	}
 	var tableRenderer Renderer
 	if r.csv {
@@ -172,11 +270,44 @@ func (r balanceRunner) execute(cmd *cobra.Command, args []string) error {
 			Color:     r.color,
 			Thousands: r.thousands,
 			Round:     r.digits,
+			Rounding:  rounding,
+			Border:    r.border,
+			Sparkline: r.sparkline,
 		}
 	}
+	tbl := reportRenderer.Render(report)
+	if r.splitByPeriod {
+		return r.writePeriods(tableRenderer, tbl, partition)
+	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return tableRenderer.Render(reportRenderer.Render(report), out)
+	return tableRenderer.Render(tbl, out)
+}
+
+// writePeriods writes one file per period to the output directory, each
+// holding the label columns and the column of the period.
+func (r *balanceRunner) writePeriods(tableRenderer Renderer, tbl *table.Table, partition date.Partition) error {
+	ext := "txt"
+	if r.csv {
+		ext = "csv"
+	}
+	fixed := tbl.Width() - partition.Size()
+	for i, d := range partition.EndDates() {
+		cols := make([]int, 0, fixed+1)
+		for c := 0; c < fixed; c++ {
+			cols = append(cols, c)
+		}
+		cols = append(cols, fixed+i)
+		var buf bytes.Buffer
+		if err := tableRenderer.Render(tbl.SelectColumns(cols...), &buf); err != nil {
+			return err
+		}
+		name := filepath.Join(r.outDir, fmt.Sprintf("balance-%s.%s", d.Format("2006-01-02"), ext))
+		if err := atomic.WriteFile(name, &buf); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type Renderer interface {
@@ -0,0 +1,49 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/sboehler/knut/cmd/cmdtest"
+	"github.com/sebdah/goldie/v2"
+)
+
+func TestGoldenBalance(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "unvalued",
+			args: []string{"-v", "CHF", "--months", "testdata/balance/unvalued.knut"},
+		},
+		{
+			name: "drop_unvalued",
+			args: []string{"-v", "CHF", "--months", "--drop-unvalued", "testdata/balance/unvalued.knut"},
+		},
+		{
+			name: "show_zero_diff",
+			args: []string{"-a", "--months", "--diff", "--show-zero-diff", "testdata/balance/closed.knut"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := cmdtest.Run(t, CreateBalanceCommand(), append([]string{"--color=false"}, test.args...)...)
+
+			goldie.New(t, goldie.WithFixtureDir("testdata/balance")).Assert(t, test.name, got)
+		})
+	}
+}
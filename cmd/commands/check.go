@@ -16,9 +16,16 @@ package commands
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/natefinch/atomic"
+
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/date"
 	"github.com/sboehler/knut/lib/journal"
 	"github.com/sboehler/knut/lib/journal/check"
 	"github.com/sboehler/knut/lib/model"
@@ -45,8 +52,12 @@ func CreateCheckCommand() *cobra.Command {
 }
 
 type checkRunner struct {
-	write   bool
-	noCheck bool
+	write           bool
+	noCheck         bool
+	parallel        bool
+	suggestCloses   bool
+	inactiveSince   flags.DateFlag
+	appendToJournal bool
 }
 
 func (r *checkRunner) run(cmd *cobra.Command, args []string) {
@@ -60,26 +71,49 @@ func (r *checkRunner) run(cmd *cobra.Command, args []string) {
 func (r *checkRunner) setupFlags(c *cobra.Command) {
 	c.Flags().BoolVar(&r.write, "write", false, "create a complete set of assertions")
 	c.Flags().BoolVar(&r.noCheck, "no-check", false, "do not check assertions")
+	c.Flags().BoolVar(&r.parallel, "parallel", false, "check accounts concurrently")
+	c.Flags().BoolVar(&r.suggestCloses, "suggest-closes", false, "print close directives for inactive accounts without positions")
+	c.Flags().Var(&r.inactiveSince, "inactive-since", "with --suggest-closes, the date after which accounts must be inactive (default: one year ago)")
+	c.Flags().BoolVar(&r.appendToJournal, "append", false, "with --suggest-closes, append the close directives to the journal file")
+	c.MarkFlagsMutuallyExclusive("write", "suggest-closes")
 }
 
 func (r *checkRunner) execute(cmd *cobra.Command, args []string) error {
 	reg := registry.New()
 
+	if r.appendToJournal && !r.suggestCloses {
+		return fmt.Errorf("--append requires --suggest-closes")
+	}
 	j, err := journal.FromPath(cmd.Context(), reg, args[0])
 	if err != nil {
 		return err
 	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	closer := check.Closer{Since: r.inactiveSince.ValueOr(date.Today().AddDate(-1, 0, 0))}
 	checker := check.Checker{
 		Write:   r.write,
 		NoCheck: r.noCheck,
+		Warn: func(err error) {
+			diagnostics.Warnf(cmd, "%v", err)
+		},
 	}
 
-	err = j.Build().Process(
-		checker.Check(),
-	)
+	if r.parallel {
+		err = checker.Parallel(j.Build())
+	} else {
+		err = j.Build().Process(
+			checker.Check(),
+		)
+	}
 	if err != nil {
 		return err
 	}
+	if r.suggestCloses {
+		if err := j.Build().Process(closer.Suggest()); err != nil {
+			return err
+		}
+		return r.writeCloses(cmd, args[0], closer.Closes())
+	}
 	if r.write {
 		out := bufio.NewWriter(os.Stdout)
 		defer out.Flush()
@@ -97,3 +131,35 @@ func (r *checkRunner) writeFile(assertions []*model.Assertion) error {
 	}
 	return journal.Print(out, j.Build())
 }
+
+func (r *checkRunner) writeCloses(cmd *cobra.Command, path string, closes []*model.Close) error {
+	j := journal.New()
+	for _, c := range closes {
+		j.Add(c)
+	}
+	if !r.appendToJournal {
+		out := bufio.NewWriter(cmd.OutOrStdout())
+		defer out.Flush()
+		return journal.Print(out, j.Build())
+	}
+	if len(closes) == 0 {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, f); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	if err := journal.Print(&buf, j.Build()); err != nil {
+		return err
+	}
+	return atomic.WriteFile(path, &buf)
+}
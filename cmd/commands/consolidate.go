@@ -0,0 +1,112 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/natefinch/atomic"
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/journal/check"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/registry"
+)
+
+// CreateConsolidateCommand creates the command.
+func CreateConsolidateCommand() *cobra.Command {
+	var r consolidateRunner
+
+	cmd := &cobra.Command{
+		Use:   "consolidate",
+		Short: "consolidate transactions per period",
+		Long: `Replace the transactions affecting the given accounts with one aggregated transaction per period,
+preserving the net flows between accounts. The consolidated journal is printed to stdout.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(cmd)
+	return cmd
+}
+
+type consolidateRunner struct {
+	period   flags.PeriodFlag
+	interval flags.IntervalFlags
+	accounts flags.RegexFlag
+	detail   string
+}
+
+func (r *consolidateRunner) setupFlags(c *cobra.Command) {
+	r.period.Setup(c, date.Period{End: date.Today()})
+	r.interval.Setup(c, date.Monthly)
+	c.Flags().Var(&r.accounts, "account", "consolidate transactions affecting accounts matching the regex")
+	c.Flags().StringVar(&r.detail, "detail", "", "write the original transactions to the given file")
+	c.MarkFlagRequired("account")
+}
+
+func (r *consolidateRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *consolidateRunner) execute(cmd *cobra.Command, args []string) error {
+	reg := registry.New()
+	b, err := journal.FromPath(cmd.Context(), reg, args[0])
+	if err != nil {
+		return err
+	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	partition := date.NewFiscalPartition(r.period.Value().Clip(b.Period()), r.interval.ValueOrDefault(reg), 0, reg.Options().FiscalYearStart())
+	detail := journal.New()
+	consolidate := journal.Consolidate(b, partition, r.matches, func(t *model.Transaction) error {
+		return detail.Add(t)
+	})
+	j := b.Build()
+	if err := j.Process(check.Check(), consolidate); err != nil {
+		return err
+	}
+	if r.detail != "" {
+		var buf bytes.Buffer
+		if err := journal.Print(&buf, detail.Build()); err != nil {
+			return err
+		}
+		if err := atomic.WriteFile(r.detail, &buf); err != nil {
+			return err
+		}
+	}
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return journal.Print(w, j)
+}
+
+func (r *consolidateRunner) matches(t *model.Transaction) bool {
+	for _, p := range t.Postings {
+		if r.accounts.Regex().MatchString(p.Account.Name()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/sboehler/knut/cmd/cmdtest"
+	"github.com/sebdah/goldie/v2"
+)
+
+func TestGoldenConsolidate(t *testing.T) {
+	got := cmdtest.Run(t, CreateConsolidateCommand(), "--account", "Expenses", "testdata/consolidate/example.knut")
+
+	goldie.New(t, goldie.WithFixtureDir("testdata/consolidate")).Assert(t, "example", got)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/importer"
+)
+
+// createDetectCommand creates the command.
+func createDetectCommand() *cobra.Command {
+	var r detectRunner
+
+	cmd := &cobra.Command{
+		Use:   "detect",
+		Short: "detect the importer for a statement",
+		Long: `Match the beginning of the statement against the signatures of the registered importers
+and list the candidates. With --run, the matching importer is run on the statement, passing
+any arguments after -- to it.`,
+		Example: "  knut import detect --run statement.csv -- --account Assets:Bank",
+
+		Args: cobra.MinimumNArgs(1),
+
+		Run: r.run,
+	}
+	r.setupFlags(cmd)
+	return cmd
+}
+
+type detectRunner struct {
+	runImporter bool
+}
+
+func (r *detectRunner) setupFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&r.runImporter, "run", false, "run the importer if exactly one matches")
+}
+
+func (r *detectRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *detectRunner) execute(cmd *cobra.Command, args []string) error {
+	head, err := readHead(args[0])
+	if err != nil {
+		return err
+	}
+	candidates := importer.Detect(head)
+	if len(candidates) == 0 {
+		return fmt.Errorf("no importer recognizes %s", args[0])
+	}
+	if !r.runImporter {
+		w := bufio.NewWriter(cmd.OutOrStdout())
+		defer w.Flush()
+		return renderImporters(w, candidates)
+	}
+	if len(candidates) > 1 {
+		var names []string
+		for _, c := range candidates {
+			names = append(names, c.Name)
+		}
+		return fmt.Errorf("several importers recognize %s: %s", args[0], strings.Join(names, ", "))
+	}
+	c := candidates[0].Create()
+	if f := cmd.InheritedFlags().Lookup("mark-tbd"); f != nil {
+		c.Flags().AddFlag(f)
+	}
+	c.SetArgs(append(args[1:], args[0]))
+	c.SetOut(cmd.OutOrStdout())
+	c.SetErr(cmd.ErrOrStderr())
+	return c.ExecuteContext(cmd.Context())
+}
+
+// readHead reads the beginning of the file which is passed to detectors.
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, importer.DetectLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
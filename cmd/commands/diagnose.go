@@ -0,0 +1,192 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/price"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/syntax"
+)
+
+// CreateDiagnoseCommand creates the command.
+func CreateDiagnoseCommand() *cobra.Command {
+	var r diagnoseRunner
+
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "explain the valuation of a single position",
+		Long: `Print the valuation derivation for one position at the given date: the native quantity,
+the chain of price directives used to derive the normalized price, and the resulting value.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(cmd)
+	return cmd
+}
+
+type diagnoseRunner struct {
+	date      flags.DateFlag
+	account   flags.AccountFlag
+	commodity flags.CommodityFlag
+	valuation flags.CommodityFlag
+}
+
+func (r *diagnoseRunner) setupFlags(c *cobra.Command) {
+	c.Flags().Var(&r.date, "date", "the date of the position (default: today)")
+	c.Flags().Var(&r.account, "account", "the account holding the position")
+	c.Flags().Var(&r.commodity, "commodity", "the commodity of the position")
+	c.Flags().VarP(&r.valuation, "val", "v", "valuate in the given commodity")
+	c.MarkFlagRequired("account")
+	c.MarkFlagRequired("commodity")
+}
+
+func (r *diagnoseRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *diagnoseRunner) execute(cmd *cobra.Command, args []string) error {
+	reg := registry.New()
+	b, err := journal.FromPath(cmd.Context(), reg, args[0])
+	if err != nil {
+		return err
+	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	acc, err := r.account.Value(reg.Accounts())
+	if err != nil {
+		return err
+	}
+	com, err := r.commodity.Value(reg)
+	if err != nil {
+		return err
+	}
+	valuation, err := r.valuation.ValueOrDefault(reg)
+	if err != nil {
+		return err
+	}
+	if valuation == nil {
+		return fmt.Errorf("no valuation commodity, use --val or set the %q option", registry.Valuation)
+	}
+	d := diagnosis{
+		Date:      r.date.ValueOr(date.Today()),
+		Account:   acc,
+		Commodity: com,
+		Valuation: valuation,
+		prices:    make(price.Prices),
+		sources:   make(map[edge]*model.Price),
+	}
+	if err := b.Build().Process(journal.ApplySplits(reg), d.collect()); err != nil {
+		return err
+	}
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return d.write(w)
+}
+
+// diagnosis collects the information needed to explain the valuation
+// of a single position.
+type diagnosis struct {
+	Date      time.Time
+	Account   *model.Account
+	Commodity *model.Commodity
+	Valuation *model.Commodity
+
+	quantity decimal.Decimal
+
+	// prices holds the prices up to the date, as used for valuation.
+	prices price.Prices
+
+	// sources holds the latest price directive linking two commodities.
+	sources map[edge]*model.Price
+}
+
+type edge struct {
+	c1, c2 *model.Commodity
+}
+
+func newEdge(c1, c2 *model.Commodity) edge {
+	if c2.Name() < c1.Name() {
+		c1, c2 = c2, c1
+	}
+	return edge{c1, c2}
+}
+
+func (d *diagnosis) collect() *journal.Processor {
+	return &journal.Processor{
+		Price: func(p *model.Price) error {
+			if p.Date.After(d.Date) {
+				return nil
+			}
+			d.prices.Insert(p.Commodity, p.Price, p.Target)
+			d.sources[newEdge(p.Commodity, p.Target)] = p
+			return nil
+		},
+		Posting: func(t *model.Transaction, p *model.Posting) error {
+			if t.Date.After(d.Date) {
+				return nil
+			}
+			if p.Account == d.Account && p.Commodity == d.Commodity {
+				d.quantity = d.quantity.Add(p.Quantity)
+			}
+			return nil
+		},
+	}
+}
+
+func (d *diagnosis) write(w io.Writer) error {
+	fmt.Fprintf(w, "Account:   %s\n", d.Account.Name())
+	fmt.Fprintf(w, "Commodity: %s\n", d.Commodity.Name())
+	fmt.Fprintf(w, "Date:      %s\n", d.Date.Format("2006-01-02"))
+	fmt.Fprintf(w, "Quantity:  %s %s\n", d.quantity, d.Commodity.Name())
+	path, ok := d.prices.Path(d.Valuation, d.Commodity)
+	if !ok {
+		return fmt.Errorf("no price found for %s in %s on %s", d.Commodity.Name(), d.Valuation.Name(), d.Date.Format("2006-01-02"))
+	}
+	normalized := d.prices.Normalize(d.Valuation)
+	for i := 1; i < len(path); i++ {
+		c := path[i]
+		fmt.Fprintf(w, "Price:     %s %s per %s (%s)\n", normalized[c], d.Valuation.Name(), c.Name(), source(d.sources[newEdge(path[i-1], c)]))
+	}
+	fmt.Fprintf(w, "Value:     %s %s\n", price.Multiply(d.quantity, normalized[d.Commodity]), d.Valuation.Name())
+	return nil
+}
+
+// source describes where a price directive comes from.
+func source(p *model.Price) string {
+	if p.Src == nil {
+		return fmt.Sprintf("adjusted for a split on %s", p.Date.Format("2006-01-02"))
+	}
+	// Location reports the end of a range, so point it at the start of the directive.
+	loc := syntax.Range{End: p.Src.Range.Start, Text: p.Src.Range.Text}.Location()
+	return fmt.Sprintf("%s:%s: %s", p.Src.Range.Path, loc, p.Src.Range.Extract())
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/journal/check"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/account"
+	"github.com/sboehler/knut/lib/model/registry"
+)
+
+// CreateDigestCommand creates the command.
+func CreateDigestCommand() *cobra.Command {
+	var r digestRunner
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "summarize the most recent period",
+		Long: `Print a compact summary of the period ending at the given date: the change of net worth,
+the largest expense accounts, the largest transactions and the failed balance assertions.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(cmd)
+	return cmd
+}
+
+type digestRunner struct {
+	date      flags.DateFlag
+	period    string
+	valuation flags.CommodityFlag
+	top       int
+	format    string
+}
+
+func (r *digestRunner) setupFlags(c *cobra.Command) {
+	c.Flags().Var(&r.date, "date", "the end of the period (default: today)")
+	c.Flags().StringVar(&r.period, "period", "weekly", "the length of the period: daily, weekly, monthly, quarterly or yearly")
+	c.Flags().VarP(&r.valuation, "val", "v", "valuate in the given commodity")
+	c.Flags().IntVar(&r.top, "top", 5, "number of expense accounts and transactions to show")
+	c.Flags().StringVar(&r.format, "format", "text", "output format: text or markdown")
+}
+
+func (r *digestRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *digestRunner) execute(cmd *cobra.Command, args []string) error {
+	interval, err := date.ParseInterval(r.period)
+	if err != nil || interval == date.Once {
+		return fmt.Errorf("invalid period %q, want daily, weekly, monthly, quarterly or yearly", r.period)
+	}
+	if r.format != "text" && r.format != "markdown" {
+		return fmt.Errorf("invalid format %q, want text or markdown", r.format)
+	}
+	reg := registry.New()
+	b, err := journal.FromPath(cmd.Context(), reg, args[0])
+	if err != nil {
+		return err
+	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	valuation, err := r.valuation.ValueOrDefault(reg)
+	if err != nil {
+		return err
+	}
+	if valuation == nil {
+		return fmt.Errorf("no valuation commodity, use --val or set the %q option", registry.Valuation)
+	}
+	end := r.date.ValueOr(date.Today())
+	d := digest{
+		Period:    date.Period{Start: date.FiscalStartOf(end, interval, reg.Options().FiscalYearStart()), End: end},
+		Interval:  interval,
+		Valuation: valuation,
+		expenses:  make(map[*model.Account]decimal.Decimal),
+	}
+	checker := check.Checker{Fail: d.fail}
+	err = b.Build().Process(
+		checker.Check(),
+		journal.ApplySplits(reg),
+		journal.ComputePrices(valuation),
+		journal.Valuate(reg, valuation),
+		d.collect(),
+	)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	if r.format == "markdown" {
+		return d.writeMarkdown(w, r.top)
+	}
+	return d.writeText(w, r.top)
+}
+
+// digest collects the summary of a period.
+type digest struct {
+	Period    date.Period
+	Interval  date.Interval
+	Valuation *model.Commodity
+
+	before, after decimal.Decimal
+	expenses      map[*model.Account]decimal.Decimal
+	transactions  []digestTransaction
+	failures      []string
+}
+
+type digestTransaction struct {
+	*model.Transaction
+	Amount decimal.Decimal
+}
+
+func (d *digest) collect() *journal.Processor {
+	return &journal.Processor{
+		Transaction: func(t *model.Transaction) error {
+			if t.Src == nil || !d.Period.Contains(t.Date) {
+				return nil
+			}
+			var amount decimal.Decimal
+			for _, p := range t.Postings {
+				if p.Value.IsPositive() {
+					amount = amount.Add(p.Value)
+				}
+			}
+			d.transactions = append(d.transactions, digestTransaction{Transaction: t, Amount: amount})
+			return nil
+		},
+		Posting: func(t *model.Transaction, p *model.Posting) error {
+			if t.Date.After(d.Period.End) {
+				return nil
+			}
+			if p.Account.IsAL() {
+				if t.Date.Before(d.Period.Start) {
+					d.before = d.before.Add(p.Value)
+				}
+				d.after = d.after.Add(p.Value)
+			}
+			if p.Account.Type() == account.EXPENSES && d.Period.Contains(t.Date) {
+				d.expenses[p.Account] = d.expenses[p.Account].Add(p.Value)
+			}
+			return nil
+		},
+	}
+}
+
+func (d *digest) fail(err error) {
+	var e check.Error
+	if !errors.As(err, &e) {
+		return
+	}
+	a, ok := e.Directive.(*model.Assertion)
+	if !ok || !d.Period.Contains(a.Date) {
+		return
+	}
+	d.failures = append(d.failures, fmt.Sprintf("%s %s", a.Date.Format("2006-01-02"), e.Msg))
+}
+
+// topExpenses returns the n expense accounts with the largest amounts.
+func (d *digest) topExpenses(n int) []*model.Account {
+	var res []*model.Account
+	for a, v := range d.expenses {
+		if !v.IsZero() {
+			res = append(res, a)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if c := d.expenses[res[i]].Cmp(d.expenses[res[j]]); c != 0 {
+			return c > 0
+		}
+		return res[i].Name() < res[j].Name()
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// topTransactions returns the n transactions with the largest amounts.
+func (d *digest) topTransactions(n int) []digestTransaction {
+	res := append([]digestTransaction(nil), d.transactions...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Amount.GreaterThan(res[j].Amount)
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// change returns the change of net worth, consistent with the displayed
+// values.
+func (d *digest) change() decimal.Decimal {
+	return d.after.Round(2).Sub(d.before.Round(2))
+}
+
+func (d *digest) title() string {
+	return fmt.Sprintf("Digest %s to %s (%s, %s)", formatDate(d.Period.Start), formatDate(d.Period.End), d.Interval, d.Valuation.Name())
+}
+
+func (d *digest) writeText(w io.Writer, n int) error {
+	fmt.Fprintln(w, d.title())
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Net worth")
+	writeColumns(w, [][2]string{
+		{formatDate(d.Period.Start.AddDate(0, 0, -1)), formatAmount(d.before)},
+		{formatDate(d.Period.End), formatAmount(d.after)},
+		{"Change", formatAmount(d.change())},
+	})
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Top expenses")
+	var rows [][2]string
+	for _, a := range d.topExpenses(n) {
+		rows = append(rows, [2]string{a.Name(), formatAmount(d.expenses[a])})
+	}
+	writeColumns(w, rows)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Largest transactions")
+	rows = nil
+	for _, t := range d.topTransactions(n) {
+		rows = append(rows, [2]string{fmt.Sprintf("%s %s", formatDate(t.Date), t.Description), formatAmount(t.Amount)})
+	}
+	writeColumns(w, rows)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Failed assertions")
+	if len(d.failures) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, f := range d.failures {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+	return nil
+}
+
+func (d *digest) writeMarkdown(w io.Writer, n int) error {
+	fmt.Fprintf(w, "## %s\n\n", d.title())
+	fmt.Fprintf(w, "### Net worth\n\n")
+	fmt.Fprintf(w, "| Date | %s |\n|---|--:|\n", d.Valuation.Name())
+	fmt.Fprintf(w, "| %s | %s |\n", formatDate(d.Period.Start.AddDate(0, 0, -1)), formatAmount(d.before))
+	fmt.Fprintf(w, "| %s | %s |\n", formatDate(d.Period.End), formatAmount(d.after))
+	fmt.Fprintf(w, "| Change | %s |\n\n", formatAmount(d.change()))
+	fmt.Fprintf(w, "### Top expenses\n\n")
+	fmt.Fprintf(w, "| Account | %s |\n|---|--:|\n", d.Valuation.Name())
+	for _, a := range d.topExpenses(n) {
+		fmt.Fprintf(w, "| %s | %s |\n", a.Name(), formatAmount(d.expenses[a]))
+	}
+	fmt.Fprintf(w, "\n### Largest transactions\n\n")
+	fmt.Fprintf(w, "| Date | Description | %s |\n|---|---|--:|\n", d.Valuation.Name())
+	for _, t := range d.topTransactions(n) {
+		fmt.Fprintf(w, "| %s | %s | %s |\n", formatDate(t.Date), strings.ReplaceAll(t.Description, "|", `\|`), formatAmount(t.Amount))
+	}
+	fmt.Fprintf(w, "\n### Failed assertions\n\n")
+	if len(d.failures) == 0 {
+		fmt.Fprintln(w, "None.")
+	}
+	for _, f := range d.failures {
+		fmt.Fprintf(w, "- %s\n", f)
+	}
+	return nil
+}
+
+// writeColumns writes the rows indented, with the labels left-aligned and
+// the amounts right-aligned.
+func writeColumns(w io.Writer, rows [][2]string) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "  none")
+		return
+	}
+	var l, r int
+	for _, row := range rows {
+		l, r = max(l, len([]rune(row[0]))), max(r, len(row[1]))
+	}
+	for _, row := range rows {
+		fmt.Fprintf(w, "  %-*s  %*s\n", l, row[0], r, row[1])
+	}
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func formatAmount(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
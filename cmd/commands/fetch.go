@@ -16,6 +16,7 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,6 +31,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/sourcegraph/conc/pool"
 	"go.uber.org/multierr"
+	"golang.org/x/exp/slices"
 
 	"github.com/cheggaaa/pb/v3"
 	"github.com/natefinch/atomic"
@@ -40,18 +42,32 @@ import (
 // CreateFetchCommand creates the command.
 func CreateFetchCommand() *cobra.Command {
 	var runner fetchRunner
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "fetch",
 		Short: "Fetch quotes from Yahoo! Finance",
-		Long:  `Fetch quotes from Yahoo! Finance based on the supplied configuration in yaml format. See doc/prices.yaml for an example.`,
+		Long:  `Fetch quotes from Yahoo! Finance based on the supplied configuration in yaml format (or json, if the file has a .json extension). See doc/prices.yaml for an example.`,
 
 		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 
 		Run: runner.run,
 	}
+	runner.setupFlags(cmd)
+	return cmd
 }
 
-type fetchRunner struct{}
+type fetchRunner struct {
+	timeout time.Duration
+	retries int
+	skip    []string
+	only    string
+}
+
+func (r *fetchRunner) setupFlags(c *cobra.Command) {
+	c.Flags().DurationVar(&r.timeout, "timeout", 30*time.Second, "timeout for a single HTTP request")
+	c.Flags().IntVar(&r.retries, "retry", 3, "number of retries after a network error or a server error")
+	c.Flags().StringSliceVar(&r.skip, "skip", nil, "skip configurations for the given commodities (e.g. currencies)")
+	c.Flags().StringVar(&r.only, "only", "", "only fetch the given symbol")
+}
 
 func (r *fetchRunner) run(cmd *cobra.Command, args []string) {
 	if err := r.execute(cmd, args); err != nil {
@@ -68,6 +84,9 @@ func (r *fetchRunner) execute(_ *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if configs, err = r.filter(configs); err != nil {
+		return err
+	}
 	p := pool.New().WithMaxGoroutines(fetchConcurrency).WithErrors()
 	bar := pb.StartNew(len(configs))
 
@@ -96,15 +115,44 @@ func (r *fetchRunner) fetch(reg *registry.Registry, f string, cfg fetchConfig) e
 	return nil
 }
 
+func (r *fetchRunner) filter(configs []fetchConfig) ([]fetchConfig, error) {
+	var (
+		res     []fetchConfig
+		symbols []string
+	)
+	for _, cfg := range configs {
+		symbols = append(symbols, cfg.Symbol)
+		if r.only != "" && cfg.Symbol != r.only {
+			continue
+		}
+		if slices.Contains(r.skip, cfg.Commodity) {
+			continue
+		}
+		res = append(res, cfg)
+	}
+	if r.only != "" && !slices.Contains(symbols, r.only) {
+		return nil, fmt.Errorf("unknown symbol %q for --only, want one of %q", r.only, symbols)
+	}
+	return res, nil
+}
+
 func (r *fetchRunner) readConfig(path string) ([]fetchConfig, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
+	var t []fetchConfig
+	if filepath.Ext(path) == ".json" {
+		dec := json.NewDecoder(f)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
 	dec := yaml.NewDecoder(f)
 	dec.SetStrict(true)
-	var t []fetchConfig
 	if err := dec.Decode(&t); err != nil {
 		return nil, err
 	}
@@ -131,9 +179,16 @@ func (r *fetchRunner) readFile(ctx *registry.Registry, filepath string) (res map
 	return prices, nil
 }
 
+func (r *fetchRunner) client() yahoo2.Client {
+	c := yahoo2.New()
+	c.Timeout = r.timeout
+	c.Retries = r.retries
+	return c
+}
+
 func (r *fetchRunner) fetchPrices(reg *registry.Registry, cfg fetchConfig, t0, t1 time.Time, results map[time.Time]*model.Price) error {
 	var (
-		c                 = yahoo2.New()
+		c                 = r.client()
 		quotes            []yahoo2.Quote
 		commodity, target *model.Commodity
 		err               error
@@ -148,16 +203,36 @@ func (r *fetchRunner) fetchPrices(reg *registry.Registry, cfg fetchConfig, t0, t
 		return err
 	}
 	for _, quote := range quotes {
+		price, err := quotePrice(cfg, quote)
+		if err != nil {
+			return err
+		}
 		results[quote.Date] = &model.Price{
 			Date:      quote.Date,
 			Commodity: commodity,
 			Target:    target,
-			Price:     decimal.NewFromFloat(quote.Close),
+			Price:     price,
 		}
 	}
 	return nil
 }
 
+// invertPrecision is the number of decimal places kept when inverting quotes.
+const invertPrecision = 10
+
+// quotePrice returns the price of the commodity in the target commodity
+// given by the quote.
+func quotePrice(cfg fetchConfig, quote yahoo2.Quote) (decimal.Decimal, error) {
+	price := decimal.NewFromFloat(quote.Close)
+	if !cfg.Invert {
+		return price, nil
+	}
+	if price.IsZero() {
+		return decimal.Zero, fmt.Errorf("error inverting quote for symbol %s on %s: price is zero", cfg.Symbol, quote.Date.Format("2006-01-02"))
+	}
+	return decimal.NewFromInt(1).DivRound(price, invertPrecision), nil
+}
+
 func (r *fetchRunner) writeFile(prices map[time.Time]*model.Price, filepath string) error {
 	j := journal.New()
 	for _, price := range prices {
@@ -172,8 +247,12 @@ func (r *fetchRunner) writeFile(prices map[time.Time]*model.Price, filepath stri
 }
 
 type fetchConfig struct {
-	Symbol          string `yaml:"symbol"`
-	File            string `yaml:"file"`
-	Commodity       string `yaml:"commodity"`
-	TargetCommodity string `yaml:"target_commodity"`
+	Symbol          string `yaml:"symbol" json:"symbol"`
+	File            string `yaml:"file" json:"file"`
+	Commodity       string `yaml:"commodity" json:"commodity"`
+	TargetCommodity string `yaml:"target_commodity" json:"target_commodity"`
+
+	// Invert indicates that the symbol quotes the price of the target
+	// commodity in the commodity, so the reciprocal is stored.
+	Invert bool `yaml:"invert" json:"invert"`
 }
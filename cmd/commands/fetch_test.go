@@ -0,0 +1,152 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sboehler/knut/lib/quotes/yahoo2"
+)
+
+func TestFetchFilter(t *testing.T) {
+	configs := []fetchConfig{
+		{Symbol: "USDCHF=X", Commodity: "USD"},
+		{Symbol: "AAPL", Commodity: "AAPL"},
+	}
+	tests := []struct {
+		desc    string
+		runner  fetchRunner
+		want    []string
+		wantErr bool
+	}{
+		{desc: "all", want: []string{"USDCHF=X", "AAPL"}},
+		{desc: "skip", runner: fetchRunner{skip: []string{"USD"}}, want: []string{"AAPL"}},
+		{desc: "only", runner: fetchRunner{only: "AAPL"}, want: []string{"AAPL"}},
+		{desc: "unknown only", runner: fetchRunner{only: "MSFT"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := test.runner.filter(configs)
+
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("filter() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filter(): unexpected error %v", err)
+			}
+			var symbols []string
+			for _, cfg := range got {
+				symbols = append(symbols, cfg.Symbol)
+			}
+			if diff := cmp.Diff(test.want, symbols); diff != "" {
+				t.Fatalf("filter(): unexpected diff (-want/+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQuotePrice(t *testing.T) {
+	date := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		desc    string
+		cfg     fetchConfig
+		close   float64
+		want    string
+		wantErr bool
+	}{
+		{desc: "plain", cfg: fetchConfig{Symbol: "CHFUSD=X"}, close: 1.08, want: "1.08"},
+		{desc: "inverted", cfg: fetchConfig{Symbol: "CHFUSD=X", Invert: true}, close: 4, want: "0.25"},
+		{desc: "inverted keeps 10 decimal places", cfg: fetchConfig{Symbol: "CHFUSD=X", Invert: true}, close: 3, want: "0.3333333333"},
+		{desc: "inverted zero", cfg: fetchConfig{Symbol: "CHFUSD=X", Invert: true}, close: 0, wantErr: true},
+		{desc: "plain zero", cfg: fetchConfig{Symbol: "CHFUSD=X"}, close: 0, want: "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := quotePrice(test.cfg, yahoo2.Quote{Date: date, Close: test.close})
+
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("quotePrice() returned %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("quotePrice(): unexpected error %v", err)
+			}
+			if got.String() != test.want {
+				t.Fatalf("quotePrice() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFetchReadConfig(t *testing.T) {
+	var r fetchRunner
+	want, err := r.readConfig("../../doc/prices.yaml")
+	if err != nil {
+		t.Fatalf("readConfig(): unexpected error %v", err)
+	}
+
+	got, err := r.readConfig("../../doc/prices.json")
+
+	if err != nil {
+		t.Fatalf("readConfig(): unexpected error %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("readConfig(): unexpected diff (-yaml/+json):\n%s", diff)
+	}
+}
+
+func TestFetchReadConfigDecodesJSONByExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	if err := os.WriteFile(path, []byte("- symbol: AAPL\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var r fetchRunner
+
+	if _, err := r.readConfig(path); err == nil {
+		t.Fatalf("readConfig() decoded yaml in a .json file, want an error")
+	}
+}
+
+func TestFetchReadConfigRejectsUnknownFields(t *testing.T) {
+	tests := []struct {
+		file, content string
+	}{
+		{"prices.json", `[{"symbol": "AAPL", "inverted": true}]`},
+		{"prices.yaml", "- symbol: AAPL\n  inverted: true\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var r fetchRunner
+
+			if _, err := r.readConfig(path); err == nil {
+				t.Fatalf("readConfig() returned no error, want an error for the unknown field")
+			}
+		})
+	}
+}
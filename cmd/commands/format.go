@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/natefinch/atomic"
 	"github.com/sourcegraph/conc/iter"
@@ -25,34 +26,46 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/sboehler/knut/lib/syntax"
+	"github.com/sboehler/knut/lib/syntax/parser"
 )
 
 // CreateFormatCommand creates the command.
 func CreateFormatCommand() *cobra.Command {
 	var runner formatRunner
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "format",
 		Short: "Format the given journal",
-		Long:  `Format the given journal in-place. Any white space and comments between directives is preserved.`,
+		Long: `Format the given journal in-place. Any white space and comments between directives is preserved.
+
+With --verify, the formatted journal is parsed again and compared to the original instead of
+being written. Any directive which does not survive the round trip is reported.`,
 
 		Run: runner.run,
 	}
+	runner.setupFlags(cmd)
+	return cmd
+}
+
+type formatRunner struct {
+	verify bool
 }
 
-type formatRunner struct{}
+func (r *formatRunner) setupFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&r.verify, "verify", false, "check that formatting preserves all directives, without writing")
+}
 
-func (r formatRunner) run(cmd *cobra.Command, args []string) {
+func (r *formatRunner) run(cmd *cobra.Command, args []string) {
 	if err := r.execute(cmd, args); err != nil {
 		fmt.Fprintln(cmd.ErrOrStderr(), err)
 		os.Exit(1)
 	}
 }
 
-func (r formatRunner) execute(cmd *cobra.Command, args []string) error {
+func (r *formatRunner) execute(cmd *cobra.Command, args []string) error {
 	return multierr.Combine(iter.Map(args, r.formatFile)...)
 }
 
-func (formatRunner) formatFile(target *string) error {
+func (r *formatRunner) formatFile(target *string) error {
 	file, err := syntax.ParseFile(*target)
 	if err != nil {
 		return err
@@ -61,5 +74,124 @@ func (formatRunner) formatFile(target *string) error {
 	if err := syntax.FormatFile(&dest, file); err != nil {
 		return err
 	}
+	if r.verify {
+		return verifyRoundTrip(file, dest.String())
+	}
 	return atomic.WriteFile(*target, &dest)
 }
+
+// verifyRoundTrip parses the formatted text and checks that its directives
+// have the same fields as those of the original file. The fields are taken
+// from the source text of both parses, so anything the printer drops or
+// changes is detected.
+func verifyRoundTrip(file syntax.File, formatted string) error {
+	p := parser.New(formatted, file.Path)
+	if err := p.Advance(); err != nil {
+		return err
+	}
+	res, err := p.ParseFile()
+	if err != nil {
+		return fmt.Errorf("%s: formatted journal cannot be parsed: %w", file.Path, err)
+	}
+	var errs error
+	for i, d := range file.Directives {
+		if i >= len(res.Directives) {
+			errs = multierr.Append(errs, divergence(d, "missing after formatting"))
+			continue
+		}
+		want, got := fields(d), fields(res.Directives[i])
+		if k, ok := firstDifference(want, got); ok {
+			errs = multierr.Append(errs, divergence(d, fmt.Sprintf("%s changed, formatted as:\n%s", k, res.Directives[i].Extract())))
+		}
+	}
+	if len(res.Directives) > len(file.Directives) {
+		errs = multierr.Append(errs, fmt.Errorf("%s: formatting added %d directive(s)", file.Path, len(res.Directives)-len(file.Directives)))
+	}
+	return errs
+}
+
+// field is a named part of a directive, with its source text.
+type field struct {
+	name, text string
+}
+
+// fields returns the semantic parts of the directive.
+func fields(d syntax.Directive) []field {
+	fs := []field{{"directive", fmt.Sprintf("%T", d.Directive)}}
+	add := func(name string, r syntax.Range) {
+		fs = append(fs, field{name, strings.TrimSpace(r.Extract())})
+	}
+	switch d := d.Directive.(type) {
+	case syntax.Transaction:
+		add("date", d.Date.Range)
+		add("description", d.Description.Content)
+		for _, t := range d.Addons.Performance.Targets {
+			add("performance target", t.Range)
+		}
+		add("accrual interval", d.Addons.Accrual.Interval.Range)
+		add("accrual start", d.Addons.Accrual.Start.Range)
+		add("accrual end", d.Addons.Accrual.End.Range)
+		add("accrual account", d.Addons.Accrual.Account.Range)
+		for _, b := range d.Bookings {
+			add("credit account", b.Credit.Range)
+			add("debit account", b.Debit.Range)
+			add("quantity", b.Quantity.Range)
+			add("commodity", b.Commodity.Range)
+			add("comment", b.Comment)
+		}
+	case syntax.Open:
+		add("date", d.Date.Range)
+		add("account", d.Account.Range)
+	case syntax.Close:
+		add("date", d.Date.Range)
+		add("account", d.Account.Range)
+	case syntax.Assertion:
+		add("date", d.Date.Range)
+		for _, b := range d.Balances {
+			add("account", b.Account.Range)
+			add("quantity", b.Quantity.Range)
+			add("commodity", b.Commodity.Range)
+		}
+	case syntax.Price:
+		add("date", d.Date.Range)
+		add("commodity", d.Commodity.Range)
+		add("price", d.Price.Range)
+		add("target", d.Target.Range)
+	case syntax.Split:
+		add("date", d.Date.Range)
+		add("commodity", d.Commodity.Range)
+		add("numerator", d.Numerator.Range)
+		add("denominator", d.Denominator.Range)
+	case syntax.Include:
+		add("path", d.IncludePath.Content)
+	case syntax.Alias:
+		add("name", d.Name)
+		add("account", d.Account.Range)
+	case syntax.Option:
+		add("key", d.Key.Content)
+		add("value", d.Value.Content)
+	}
+	return fs
+}
+
+// firstDifference returns the name of the first field which differs.
+func firstDifference(want, got []field) (string, bool) {
+	for i, f := range want {
+		if i >= len(got) {
+			return f.name, true
+		}
+		if got[i] != f {
+			return f.name, true
+		}
+	}
+	if len(got) > len(want) {
+		return got[len(want)].name, true
+	}
+	return "", false
+}
+
+func divergence(d syntax.Directive, msg string) error {
+	// Location reports the end of a range, so point it at the start of the directive.
+	loc := syntax.Range{End: d.Start, Text: d.Text}.Location()
+	return fmt.Errorf("%s:%s: directive does not survive the round trip, %s:\n%s", d.Path, loc, msg, d.Extract())
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sboehler/knut/lib/syntax/parser"
+)
+
+const formatJournal = `option "valuation" "CHF"
+
+2020-01-01   open    Assets:Bank
+
+@performance(USD)
+2020-01-02 "salary"
+Income:Salary   Assets:Bank   1000.50 CHF # january
+Income:Salary   Assets:Bank   10 USD
+
+2020-01-03 balance Assets:Bank 1000.50 CHF
+
+2020-01-04 price USD 0.9 CHF
+
+2020-01-05 close Assets:Bank
+`
+
+func TestVerifyRoundTrip(t *testing.T) {
+	tests := []struct {
+		desc      string
+		formatted func(string) string
+		wantErr   string
+	}{
+		{
+			desc:      "unchanged",
+			formatted: func(s string) string { return s },
+		},
+		{
+			desc:      "whitespace only",
+			formatted: func(s string) string { return strings.ReplaceAll(s, "   ", " ") },
+		},
+		{
+			desc:      "dropped booking",
+			formatted: func(s string) string { return strings.Replace(s, "Income:Salary   Assets:Bank   10 USD\n", "", 1) },
+			wantErr:   "credit account changed",
+		},
+		{
+			desc:      "truncated quantity",
+			formatted: func(s string) string { return strings.Replace(s, "1000.50 CHF #", "1000.5 CHF #", 1) },
+			wantErr:   "quantity changed",
+		},
+		{
+			desc:      "changed commodity",
+			formatted: func(s string) string { return strings.Replace(s, "price USD 0.9 CHF", "price USD 0.9 EUR", 1) },
+			wantErr:   "target changed",
+		},
+		{
+			desc:      "dropped comment",
+			formatted: func(s string) string { return strings.Replace(s, " # january", "", 1) },
+			wantErr:   "comment changed",
+		},
+		{
+			desc:      "dropped addon",
+			formatted: func(s string) string { return strings.Replace(s, "@performance(USD)\n", "", 1) },
+			wantErr:   "performance target changed",
+		},
+		{
+			desc:      "changed directive",
+			formatted: func(s string) string { return strings.Replace(s, "close Assets:Bank", "open Assets:Bank", 1) },
+			wantErr:   "directive changed",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := parser.New(formatJournal, "journal.knut")
+			if err := p.Advance(); err != nil {
+				t.Fatal(err)
+			}
+			file, err := p.ParseFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifyRoundTrip(file, test.formatted(formatJournal))
+
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verifyRoundTrip(): unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("verifyRoundTrip(): got error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -15,18 +15,57 @@
 package commands
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/sboehler/knut/cmd/importer"
+	"github.com/sboehler/knut/lib/common/table"
 	"github.com/spf13/cobra"
 )
 
 // CreateImportCommand is the import command.
 func CreateImportCommand() *cobra.Command {
+	var list bool
 	cmd := cobra.Command{
 		Use:   "import",
 		Short: "Import financial account statements",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			if !list {
+				cmd.Help()
+				return
+			}
+			if err := listImporters(cmd); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+				os.Exit(1)
+			}
+		},
 	}
+	cmd.Flags().BoolVar(&list, "list", false, "list the supported formats")
+	cmd.PersistentFlags().Bool("mark-tbd", false, "mark postings of the TBD account with a \"# TODO categorize\" comment")
 	for _, constructor := range importer.GetImporters() {
 		cmd.AddCommand(constructor())
 	}
+	cmd.AddCommand(createDetectCommand())
 	return &cmd
 }
+
+func listImporters(cmd *cobra.Command) error {
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return renderImporters(w, importer.Importers())
+}
+
+func renderImporters(w io.Writer, infos []importer.Info) error {
+	tbl := table.New(1, 1)
+	tbl.AddSeparatorRow()
+	tbl.AddRow().AddText("Format", table.Center).AddText("Description", table.Center)
+	tbl.AddSeparatorRow()
+	for _, info := range infos {
+		tbl.AddRow().AddText(info.Name, table.Left).AddText(info.Description, table.Left)
+	}
+	tbl.AddSeparatorRow()
+	return (&table.TextRenderer{}).Render(tbl, w)
+}
@@ -37,7 +37,8 @@ func CreateInferCmd() *cobra.Command {
 		Use:   "infer",
 		Short: "Auto-assign accounts in a journal",
 		Long: `Build a Bayes model using the supplied training file and apply it to replace
-		the indicated account in the target file. Training file and target file may be the same.`,
+		the indicated account in the target file. Training file and target file may be the same.
+		Additional journals can be used for training with --learn-from.`,
 		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 		Run:  r.run,
 	}
@@ -48,6 +49,7 @@ func CreateInferCmd() *cobra.Command {
 type inferRunner struct {
 	account      string
 	trainingFile string
+	learnFrom    []string
 	inplace      bool
 }
 
@@ -55,7 +57,7 @@ func (r *inferRunner) setupFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&r.account, "account", "a", "Expenses:TBD", "account name")
 	cmd.Flags().BoolVarP(&r.inplace, "inplace", "i", false, "infer the accounts inplace")
 	cmd.Flags().StringVarP(&r.trainingFile, "training-file", "t", "", "the journal file with existing data")
-	cmd.MarkFlagRequired("training-file")
+	cmd.Flags().StringSliceVar(&r.learnFrom, "learn-from", nil, "additional categorized journals to train the model with")
 }
 
 func (r *inferRunner) run(cmd *cobra.Command, args []string) {
@@ -70,9 +72,18 @@ func (r *inferRunner) execute(cmd *cobra.Command, args []string) (errors error)
 		targetFile = args[0]
 		err        error
 	)
-	model, err := r.train(cmd.Context(), r.trainingFile, r.account)
-	if err != nil {
-		return err
+	files := r.learnFrom
+	if r.trainingFile != "" {
+		files = append([]string{r.trainingFile}, files...)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("either --training-file or --learn-from is required")
+	}
+	model := bayes.NewModel(r.account)
+	for _, f := range files {
+		if err := r.train(cmd.Context(), model, f); err != nil {
+			return err
+		}
 	}
 	file, err := r.parseAndInfer(cmd.Context(), model, targetFile)
 	if err != nil {
@@ -91,8 +102,7 @@ func (r *inferRunner) execute(cmd *cobra.Command, args []string) (errors error)
 	}
 }
 
-func (inferRunner) train(ctx context.Context, file string, account string) (*bayes.Model, error) {
-	model := bayes.NewModel(account)
+func (inferRunner) train(ctx context.Context, model *bayes.Model, file string) error {
 	p := pool.New().WithErrors().WithFirstError().WithContext(ctx)
 	ch, worker := syntax.ParseFileRecursively(file)
 	p.Go(worker)
@@ -106,7 +116,7 @@ func (inferRunner) train(ctx context.Context, file string, account string) (*bay
 			return nil
 		})
 	})
-	return model, p.Wait()
+	return p.Wait()
 }
 
 func (r *inferRunner) parseAndInfer(ctx context.Context, model *bayes.Model, targetFile string) (syntax.File, error) {
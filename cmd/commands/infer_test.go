@@ -28,3 +28,10 @@ func TestInfer(t *testing.T) {
 
 	goldie.New(t, goldie.WithFixtureDir("testdata/infer")).Assert(t, "target", got)
 }
+
+func TestInferLearnFrom(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateInferCmd(), "--learn-from", "testdata/infer/training.knut", "testdata/infer/target.knut")
+
+	goldie.New(t, goldie.WithFixtureDir("testdata/infer")).Assert(t, "target", got)
+}
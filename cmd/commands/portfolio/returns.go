@@ -14,13 +14,20 @@
 package portfolio
 
 import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"runtime/pprof"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/sboehler/knut/cmd/diagnostics"
 	"github.com/sboehler/knut/cmd/flags"
 	"github.com/sboehler/knut/lib/common/predicate"
 	"github.com/sboehler/knut/lib/journal"
@@ -53,6 +60,8 @@ type returnsRunner struct {
 	cpuprofile            string
 	valuation             flags.CommodityFlag
 	accounts, commodities flags.RegexFlag
+	cumulative            bool
+	format                string
 }
 
 func (r *returnsRunner) setupFlags(cmd *cobra.Command) {
@@ -61,6 +70,8 @@ func (r *returnsRunner) setupFlags(cmd *cobra.Command) {
 	cmd.Flags().VarP(&r.valuation, "val", "v", "valuate in the given commodity")
 	cmd.Flags().Var(&r.accounts, "account", "filter accounts with a regex")
 	cmd.Flags().Var(&r.commodities, "commodity", "filter commodities with a regex")
+	cmd.Flags().BoolVar(&r.cumulative, "cumulative", false, "print the growth of 1 unit of the valuation commodity instead of period returns")
+	cmd.Flags().StringVar(&r.format, "format", "text", "output format: text, csv or json")
 }
 
 func (r *returnsRunner) run(cmd *cobra.Command, args []string) {
@@ -81,28 +92,123 @@ func (r *returnsRunner) run(cmd *cobra.Command, args []string) {
 func (r *returnsRunner) execute(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	reg := registry.New()
-	valuation, err := r.valuation.Value(reg)
+	if r.format != "text" && r.format != "csv" && r.format != "json" {
+		return fmt.Errorf("invalid format %q, want text, csv or json", r.format)
+	}
+	j, err := journal.FromPath(ctx, reg, args[0])
 	if err != nil {
 		return err
 	}
-	j, err := journal.FromPath(ctx, reg, args[0])
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	valuation, err := r.valuation.ValueOrDefault(reg)
 	if err != nil {
 		return err
 	}
-	partition := r.Multiperiod.Partition(j.Period())
+	partition := r.Multiperiod.Partition(reg, j.Period())
 	calculator := &performance.Calculator{
 		Context:         reg,
 		Valuation:       valuation,
 		AccountFilter:   predicate.ByName[*model.Account](r.accounts.Regex()),
 		CommodityFilter: predicate.ByName[*model.Commodity](r.commodities.Regex()),
 	}
+	var series []returnsPoint
+	growth := 1.0
+	returns := performance.Returns(j, partition, func(d time.Time, rtn float64) error {
+		value := rtn
+		if r.cumulative {
+			growth *= 1 + rtn
+			value = growth
+		}
+		series = append(series, returnsPoint{Date: d, Value: value})
+		return nil
+	})
 	err = j.Build().Process(
-		journal.ComputePrices(valuation),
 		check.Check(),
+		journal.ApplySplits(reg),
+		journal.ComputePrices(valuation),
 		journal.Valuate(reg, valuation),
 		calculator.ComputeValues(),
 		calculator.ComputeFlows(),
-		performance.Perf(j, partition),
+		returns,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return r.render(w, valuation, series)
+}
+
+// SchemaVersion is the version of the JSON output of the returns command.
+// It is incremented on incompatible changes.
+const SchemaVersion = 1
+
+// JSONReturns is the JSON output of the returns command. Accounts and
+// Commodities hold the filters which select the portfolio.
+type JSONReturns struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Valuation     string       `json:"valuation"`
+	Cumulative    bool         `json:"cumulative"`
+	Accounts      []string     `json:"accounts,omitempty"`
+	Commodities   []string     `json:"commodities,omitempty"`
+	Returns       []JSONReturn `json:"returns"`
+}
+
+// JSONReturn is an element of the JSON output of the returns command. Value
+// is the period return, or the growth of 1 unit if --cumulative is given.
+type JSONReturn struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+type returnsPoint struct {
+	Date  time.Time
+	Value float64
+}
+
+func (r *returnsRunner) render(w io.Writer, valuation *model.Commodity, series []returnsPoint) error {
+	switch {
+	case r.format == "csv":
+		cw := csv.NewWriter(w)
+		header := "return"
+		if r.cumulative {
+			header = "value"
+		}
+		cw.Write([]string{"date", header})
+		for _, p := range series {
+			cw.Write([]string{p.Date.Format("2006-01-02"), strconv.FormatFloat(p.Value, 'f', 6, 64)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case r.format == "json":
+		res := JSONReturns{
+			SchemaVersion: SchemaVersion,
+			Cumulative:    r.cumulative,
+			Returns:       make([]JSONReturn, 0, len(series)),
+		}
+		if valuation != nil {
+			res.Valuation = valuation.Name()
+		}
+		for _, rx := range r.accounts.Regex() {
+			res.Accounts = append(res.Accounts, rx.String())
+		}
+		for _, rx := range r.commodities.Regex() {
+			res.Commodities = append(res.Commodities, rx.String())
+		}
+		for _, p := range series {
+			res.Returns = append(res.Returns, JSONReturn{p.Date.Format("2006-01-02"), p.Value})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	case r.cumulative:
+		for _, p := range series {
+			fmt.Fprintf(w, "%s: %0.4f\n", p.Date.Format("2006-01-02"), p.Value)
+		}
+	default:
+		for _, p := range series {
+			fmt.Fprintf(w, "%v: %0.1f%%\n", p.Date, 100*p.Value)
+		}
+	}
+	return nil
 }
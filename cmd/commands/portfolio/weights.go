@@ -21,6 +21,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/sboehler/knut/cmd/diagnostics"
 	"github.com/sboehler/knut/cmd/flags"
 	"github.com/sboehler/knut/lib/common/predicate"
 	"github.com/sboehler/knut/lib/common/table"
@@ -103,15 +104,19 @@ func (r *weightsRunner) execute(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
-	valuation, err := r.valuation.Value(reg)
+	j, err := journal.FromPath(ctx, reg, args[0])
 	if err != nil {
 		return err
 	}
-	j, err := journal.FromPath(ctx, reg, args[0])
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	valuation, err := r.valuation.ValueOrDefault(reg)
 	if err != nil {
 		return err
 	}
-	partition := r.Multiperiod.Partition(j.Period())
+	if p, ok := reg.Options().Precision(); ok && !cmd.Flags().Changed("digits") {
+		r.digits = p
+	}
+	partition := r.Multiperiod.Partition(reg, j.Period())
 	calculator := &performance.Calculator{
 		Context:         reg,
 		Valuation:       valuation,
@@ -121,8 +126,9 @@ func (r *weightsRunner) execute(cmd *cobra.Command, args []string) error {
 	j.Days(partition.EndDates())
 	rep := weights.NewReport()
 	err = j.Build().Process(
-		journal.ComputePrices(valuation),
 		check.Check(),
+		journal.ApplySplits(reg),
+		journal.ComputePrices(valuation),
 		journal.Valuate(reg, valuation),
 		calculator.ComputeValues(),
 		weights.Query{
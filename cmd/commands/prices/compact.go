@@ -0,0 +1,158 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prices
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/natefinch/atomic"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/price"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/syntax"
+)
+
+// CreateCompactCommand creates the command.
+func CreateCompactCommand() *cobra.Command {
+	var r compactRunner
+	c := &cobra.Command{
+		Use:   "compact",
+		Short: "drop redundant prices from a prices file",
+		Long: `Drop prices which are within a relative tolerance of the previous kept price of the same
+commodity pair. The first and the last price of each pair are always kept. With the default
+tolerance of zero, only repetitions of the same price are dropped. The compacted prices are
+printed to stdout.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(c)
+	return c
+}
+
+type compactRunner struct {
+	tolerance float64
+	write     bool
+}
+
+func (r *compactRunner) setupFlags(c *cobra.Command) {
+	c.Flags().Float64Var(&r.tolerance, "tolerance", 0, "drop prices within the given relative distance of the previous kept price, e.g. 0.001 for 0.1%")
+	c.Flags().BoolVar(&r.write, "write", false, "overwrite the prices file instead of printing to stdout")
+}
+
+func (r *compactRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *compactRunner) execute(cmd *cobra.Command, args []string) error {
+	if r.tolerance < 0 {
+		return fmt.Errorf("invalid tolerance %v, want a nonnegative number", r.tolerance)
+	}
+	prices, err := readPrices(registry.New(), args[0])
+	if err != nil {
+		return err
+	}
+	j := journal.New()
+	for _, p := range compact(prices, decimal.NewFromFloat(r.tolerance)) {
+		if err := j.Add(p); err != nil {
+			return err
+		}
+	}
+	if r.write {
+		var buf bytes.Buffer
+		if err := journal.Print(&buf, j.Build()); err != nil {
+			return err
+		}
+		return atomic.WriteFile(args[0], &buf)
+	}
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return journal.Print(w, j.Build())
+}
+
+func readPrices(reg *registry.Registry, path string) ([]*model.Price, error) {
+	f, err := syntax.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var res []*model.Price
+	for _, d := range f.Directives {
+		p, ok := d.Directive.(syntax.Price)
+		if !ok {
+			return nil, fmt.Errorf("unexpected directive in prices file: %v", d)
+		}
+		m, err := price.Create(reg, &p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// compact drops the prices which deviate from the previous kept price of
+// the same commodity pair by at most the given relative tolerance. The
+// first and the last price of each pair are kept.
+func compact(prices []*model.Price, tolerance decimal.Decimal) []*model.Price {
+	type pair struct {
+		commodity, target *model.Commodity
+	}
+	var pairs []pair
+	series := make(map[pair][]*model.Price)
+	for _, p := range prices {
+		k := pair{p.Commodity, p.Target}
+		if _, ok := series[k]; !ok {
+			pairs = append(pairs, k)
+		}
+		series[k] = append(series[k], p)
+	}
+	var res []*model.Price
+	for _, k := range pairs {
+		ps := series[k]
+		slices.SortStableFunc(ps, func(p1, p2 *model.Price) int {
+			return p1.Date.Compare(p2.Date)
+		})
+		kept := ps[0]
+		res = append(res, kept)
+		for i, p := range ps[1:] {
+			if i == len(ps)-2 || exceeds(kept.Price, p.Price, tolerance) {
+				kept = p
+				res = append(res, kept)
+			}
+		}
+	}
+	return res
+}
+
+// exceeds returns whether p deviates from ref by more than the relative
+// tolerance.
+func exceeds(ref, p, tolerance decimal.Decimal) bool {
+	if ref.IsZero() {
+		return !p.IsZero()
+	}
+	return p.Sub(ref).Div(ref).Abs().GreaterThan(tolerance)
+}
@@ -0,0 +1,23 @@
+package prices
+
+import (
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+
+	"github.com/sboehler/knut/cmd/cmdtest"
+)
+
+func TestGoldenCompact(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateCompactCommand(), "testdata/compact.knut")
+
+	goldie.New(t).Assert(t, "compact", got)
+}
+
+func TestGoldenCompactTolerance(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateCompactCommand(), "--tolerance", "0.01", "testdata/compact.knut")
+
+	goldie.New(t).Assert(t, "compact_tolerance", got)
+}
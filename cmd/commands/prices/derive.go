@@ -0,0 +1,146 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prices
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/journal/check"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/registry"
+)
+
+// CreateDeriveCommand creates the command.
+func CreateDeriveCommand() *cobra.Command {
+	var r deriveRunner
+	c := &cobra.Command{
+		Use:   "derive",
+		Short: "derive prices from transactions",
+		Long: `Derive price directives from transactions which exchange exactly one commodity against the
+given base commodity. Postings against income and expense accounts, such as fees, are ignored.
+Transactions which do not imply a price for a single commodity are skipped. The prices are
+printed to stdout.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(c)
+	return c
+}
+
+type deriveRunner struct {
+	valuation flags.CommodityFlag
+}
+
+func (r *deriveRunner) setupFlags(c *cobra.Command) {
+	c.Flags().VarP(&r.valuation, "val", "v", "the base commodity of the prices")
+}
+
+func (r *deriveRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *deriveRunner) execute(cmd *cobra.Command, args []string) error {
+	reg := registry.New()
+	b, err := journal.FromPath(cmd.Context(), reg, args[0])
+	if err != nil {
+		return err
+	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	base, err := r.valuation.ValueOrDefault(reg)
+	if err != nil {
+		return err
+	}
+	if base == nil {
+		return fmt.Errorf("no valuation commodity, use --val or set the %q option", registry.Valuation)
+	}
+	type key struct {
+		date      time.Time
+		commodity *model.Commodity
+	}
+	derived := make(map[key]*model.Price)
+	err = b.Build().Process(
+		check.Check(),
+		&journal.Processor{
+			Transaction: func(t *model.Transaction) error {
+				if p, ok := derive(t, base); ok {
+					derived[key{p.Date, p.Commodity}] = p
+				}
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	res := journal.New()
+	for _, p := range derived {
+		if err := res.Add(p); err != nil {
+			return err
+		}
+	}
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return journal.Print(w, res.Build())
+}
+
+// derive returns the price implied by the transaction, if it exchanges
+// exactly one commodity against the base commodity.
+func derive(t *model.Transaction, base *model.Commodity) (*model.Price, bool) {
+	flows := make(map[*model.Commodity]decimal.Decimal)
+	for _, p := range t.Postings {
+		if !p.Account.IsAL() || p.Other.IsIE() {
+			continue
+		}
+		flows[p.Commodity] = flows[p.Commodity].Add(p.Quantity)
+	}
+	var (
+		com      *model.Commodity
+		qty, amt decimal.Decimal
+	)
+	for c, q := range flows {
+		switch {
+		case q.IsZero():
+		case c == base:
+			amt = q
+		case com != nil:
+			return nil, false
+		default:
+			com, qty = c, q
+		}
+	}
+	if com == nil || amt.IsZero() || qty.Sign() == amt.Sign() {
+		return nil, false
+	}
+	return &model.Price{
+		Date:      t.Date,
+		Commodity: com,
+		Price:     amt.Div(qty).Abs(),
+		Target:    base,
+	}, true
+}
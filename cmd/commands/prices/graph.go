@@ -0,0 +1,180 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prices
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/common/set"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/commodity"
+	"github.com/sboehler/knut/lib/model/price"
+	"github.com/sboehler/knut/lib/model/registry"
+)
+
+// CreateGraphCommand creates the command.
+func CreateGraphCommand() *cobra.Command {
+	var r graphRunner
+	c := &cobra.Command{
+		Use:   "graph",
+		Short: "print the price graph in Graphviz format",
+		Long: `Print a Graphviz graph with the commodities as nodes and the latest price of each commodity
+pair at the given date as edges. Commodities which are used in transactions or prices but have
+no path to the valuation commodity are stranded and highlighted in red, as positions in them
+cannot be valuated.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(c)
+	return c
+}
+
+type graphRunner struct {
+	date      flags.DateFlag
+	valuation flags.CommodityFlag
+}
+
+func (r *graphRunner) setupFlags(c *cobra.Command) {
+	c.Flags().Var(&r.date, "date", "the date of the prices (default: today)")
+	c.Flags().VarP(&r.valuation, "val", "v", "the valuation commodity")
+}
+
+func (r *graphRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *graphRunner) execute(cmd *cobra.Command, args []string) error {
+	reg := registry.New()
+	b, err := journal.FromPath(cmd.Context(), reg, args[0])
+	if err != nil {
+		return err
+	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	valuation, err := r.valuation.ValueOrDefault(reg)
+	if err != nil {
+		return err
+	}
+	if valuation == nil {
+		return fmt.Errorf("no valuation commodity, use --val or set the %q option", registry.Valuation)
+	}
+	g := priceGraph{
+		Date:        r.date.ValueOr(date.Today()),
+		Valuation:   valuation,
+		commodities: set.New[*model.Commodity](),
+		edges:       make(map[edge]*model.Price),
+	}
+	day := b.Days([]time.Time{g.Date})[0]
+	if err := b.Build().Process(journal.ComputePrices(valuation), g.collect()); err != nil {
+		return err
+	}
+	g.normalized = day.Normalized
+	w := bufio.NewWriter(cmd.OutOrStdout())
+	defer w.Flush()
+	return g.write(w)
+}
+
+type edge struct {
+	c1, c2 *model.Commodity
+}
+
+// priceGraph collects the commodities and the latest price of each
+// commodity pair up to a date.
+type priceGraph struct {
+	Date      time.Time
+	Valuation *model.Commodity
+
+	commodities set.Set[*model.Commodity]
+	edges       map[edge]*model.Price
+	normalized  price.NormalizedPrices
+}
+
+func (g *priceGraph) collect() *journal.Processor {
+	return &journal.Processor{
+		Price: func(p *model.Price) error {
+			if p.Date.After(g.Date) {
+				return nil
+			}
+			g.commodities.Add(p.Commodity)
+			g.commodities.Add(p.Target)
+			c1, c2 := p.Commodity, p.Target
+			if c2.Name() < c1.Name() {
+				c1, c2 = c2, c1
+			}
+			g.edges[edge{c1, c2}] = p
+			return nil
+		},
+		Posting: func(t *model.Transaction, p *model.Posting) error {
+			if !t.Date.After(g.Date) {
+				g.commodities.Add(p.Commodity)
+			}
+			return nil
+		},
+	}
+}
+
+func (g *priceGraph) stranded(c *model.Commodity) bool {
+	_, ok := g.normalized[c]
+	return !ok && c != g.Valuation
+}
+
+func (g *priceGraph) write(w io.Writer) error {
+	g.commodities.Add(g.Valuation)
+	commodities := g.commodities.Sorted(commodity.Compare)
+	edges := make([]edge, 0, len(g.edges))
+	for e := range g.edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].c1 != edges[j].c1 {
+			return edges[i].c1.Name() < edges[j].c1.Name()
+		}
+		return edges[i].c2.Name() < edges[j].c2.Name()
+	})
+	fmt.Fprintf(w, "graph prices {\n")
+	fmt.Fprintf(w, "  label=%q;\n", fmt.Sprintf("prices on %s", g.Date.Format("2006-01-02")))
+	for _, c := range commodities {
+		switch {
+		case c == g.Valuation:
+			fmt.Fprintf(w, "  %q [shape=doublecircle];\n", c.Name())
+		case g.stranded(c):
+			fmt.Fprintf(w, "  %q [color=red, fontcolor=red];\n", c.Name())
+		default:
+			fmt.Fprintf(w, "  %q;\n", c.Name())
+		}
+	}
+	for _, e := range edges {
+		p := g.edges[e]
+		label := fmt.Sprintf("%s %s (%s)", p.Price, p.Target.Name(), p.Date.Format("2006-01-02"))
+		fmt.Fprintf(w, "  %q -- %q [label=%q];\n", p.Commodity.Name(), p.Target.Name(), label)
+	}
+	_, err := fmt.Fprintf(w, "}\n")
+	return err
+}
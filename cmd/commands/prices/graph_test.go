@@ -0,0 +1,16 @@
+package prices
+
+import (
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+
+	"github.com/sboehler/knut/cmd/cmdtest"
+)
+
+func TestGoldenGraph(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateGraphCommand(), "-v", "CHF", "--date", "2024-06-30", "testdata/graph.knut")
+
+	goldie.New(t).Assert(t, "graph", got)
+}
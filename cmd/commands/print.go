@@ -19,8 +19,12 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/sboehler/knut/cmd/diagnostics"
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/predicate"
 	"github.com/sboehler/knut/lib/journal"
 	"github.com/sboehler/knut/lib/journal/check"
+	"github.com/sboehler/knut/lib/model"
 	"github.com/sboehler/knut/lib/model/registry"
 
 	"github.com/spf13/cobra"
@@ -45,9 +49,14 @@ func CreatePrintCommand() *cobra.Command {
 }
 
 type printRunner struct {
+	fromTransaction, toTransaction flags.RegexFlag
+	markTBD                        bool
 }
 
 func (r *printRunner) setupFlags(c *cobra.Command) {
+	c.Flags().Var(&r.fromTransaction, "from-transaction", "start at the first transaction whose description matches the regex")
+	c.Flags().Var(&r.toTransaction, "to-transaction", "end at the first transaction whose description matches the regex")
+	c.Flags().BoolVar(&r.markTBD, "mark-tbd", false, "mark postings of the TBD account with a \"# TODO categorize\" comment")
 }
 
 func (r *printRunner) run(cmd *cobra.Command, args []string) {
@@ -63,10 +72,27 @@ func (r *printRunner) execute(cmd *cobra.Command, args []string) (errors error)
 	if err != nil {
 		return err
 	}
-	if err := j.Build().Process(check.Check()); err != nil {
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	slice := journal.Slice{
+		From: r.marker(r.fromTransaction),
+		To:   r.marker(r.toTransaction),
+	}
+	if err := j.Build().Process(check.Check(), journal.Sort(), slice.Process()); err != nil {
+		return err
+	}
+	if err := slice.Err(); err != nil {
 		return err
 	}
 	w := bufio.NewWriter(cmd.OutOrStdout())
 	defer w.Flush()
-	return journal.Print(w, j.Build())
+	return journal.Printer{MarkTBD: r.markTBD}.Print(w, j.Build())
+}
+
+func (r *printRunner) marker(f flags.RegexFlag) predicate.Predicate[*model.Transaction] {
+	if len(f.Regex()) == 0 {
+		return nil
+	}
+	return func(t *model.Transaction) bool {
+		return f.Regex().MatchString(t.Description)
+	}
 }
@@ -20,7 +20,9 @@ import (
 	"log"
 	"os"
 	"runtime/pprof"
+	"text/template"
 
+	"github.com/sboehler/knut/cmd/diagnostics"
 	"github.com/sboehler/knut/cmd/flags"
 	"github.com/sboehler/knut/lib/amounts"
 	"github.com/sboehler/knut/lib/common/mapper"
@@ -64,16 +66,20 @@ type registerRunner struct {
 	// transformations
 	showCommodities               bool
 	showSource                    bool
+	collate                       bool
 	showDescriptions              bool
+	memoTemplate                  string
 	mapping                       flags.MappingFlag
 	remap                         flags.RegexFlag
 	valuation                     flags.CommodityFlag
 	accounts, others, commodities flags.RegexFlag
+	foldTransfers                 flags.RegexFlag
 
 	// formatting
 	thousands, color   bool
 	sortAlphabetically bool
 	digits             int32
+	rounding           string
 }
 
 func (r *registerRunner) run(cmd *cobra.Command, args []string) {
@@ -98,14 +104,20 @@ func (r *registerRunner) setupFlags(c *cobra.Command) {
 	c.Flags().BoolVarP(&r.sortAlphabetically, "sort", "s", false, "Sort accounts alphabetically")
 	c.Flags().BoolVarP(&r.showCommodities, "show-commodities", "c", false, "Show commodities")
 	c.Flags().BoolVarP(&r.showDescriptions, "show-descriptions", "d", false, "Show descriptions")
+	c.Flags().StringVar(&r.memoTemplate, "memo-template", "", "Go template for the description of valuation transactions, with fields .Date, .Commodity, .Account and .Gain")
 	c.Flags().BoolVarP(&r.showSource, "show-source", "a", false, "Show the source accounts")
 	c.Flags().VarP(&r.valuation, "val", "v", "valuate in the given commodity")
 	c.Flags().VarP(&r.mapping, "map", "m", "<level>,<regex>")
 	c.Flags().VarP(&r.remap, "remap", "r", "<regex>")
-	c.Flags().Var(&r.accounts, "source", "filter source accounts with a regex")
+	c.Flags().Var(&r.accounts, "source", "filter source accounts with a regex, may be repeated")
+	c.Flags().Var(&r.accounts, "account", "")
+	c.Flags().MarkDeprecated("account", "use --source instead")
+	c.Flags().BoolVar(&r.collate, "collate", false, "interleave the postings of all matching source accounts by date, labeled by account")
 	c.Flags().Var(&r.others, "dest", "filter dest accounts with a regex")
 	c.Flags().Var(&r.commodities, "commodity", "filter commodities with a regex")
+	c.Flags().Var(&r.foldTransfers, "fold-transfers", "exclude transactions between internal accounts matching the regex")
 	c.Flags().Int32Var(&r.digits, "digits", 0, "round to number of digits")
+	c.Flags().StringVar(&r.rounding, "rounding", "half-up", "rounding mode for --digits: half-up, half-even or down")
 	c.Flags().BoolVarP(&r.thousands, "thousands", "k", false, "show numbers in units of 1000")
 	c.Flags().BoolVar(&r.color, "color", true, "print output in color")
 }
@@ -113,28 +125,45 @@ func (r *registerRunner) setupFlags(c *cobra.Command) {
 func (r registerRunner) execute(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	reg := registry.New()
-	valuation, err := r.valuation.Value(reg)
+	rounding, err := table.ParseRounding(r.rounding)
 	if err != nil {
 		return err
 	}
-	r.showCommodities = r.showCommodities || valuation == nil
+	var memo *template.Template
+	if r.memoTemplate != "" {
+		if memo, err = template.New("memo").Parse(r.memoTemplate); err != nil {
+			return err
+		}
+	}
 	b, err := journal.FromPath(ctx, reg, args[0])
 	if err != nil {
 		return err
 	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	valuation, err := r.valuation.ValueOrDefault(reg)
+	if err != nil {
+		return err
+	}
+	if p, ok := reg.Options().Precision(); ok && !cmd.Flags().Changed("digits") {
+		r.digits = p
+	}
+	r.showCommodities = r.showCommodities || valuation == nil
+	r.showSource = r.showSource || r.collate
 	var am mapper.Mapper[*model.Account]
 	if r.showSource {
 		am = account.Remap(reg.Accounts(), r.remap.Regex())
 	}
-	partition := r.Multiperiod.Partition(b.Period())
+	partition := r.Multiperiod.Partition(reg, b.Period())
 	rep := register.NewReport(reg)
 	j := b.Build()
 	err = j.Process(
 		journal.Sort(),
-		journal.ComputePrices(valuation),
 		check.Check(),
-		journal.Valuate(reg, valuation),
+		journal.ApplySplits(reg),
+		journal.ComputePrices(valuation),
+		journal.Valuator{Registry: reg, Valuation: valuation, Memo: memo}.Process(),
 		journal.Filter(partition),
+		journal.FoldTransfers(r.foldTransfers.Regex()),
 		journal.Query{
 			Select: amounts.KeyMapper{
 				Date:    partition.Align(),
@@ -163,11 +192,13 @@ func (r registerRunner) execute(cmd *cobra.Command, args []string) error {
 		ShowDescriptions:   r.showDescriptions,
 		ShowSource:         r.showSource,
 		SortAlphabetically: r.sortAlphabetically,
+		Collate:            r.collate,
 	}
 	tableRenderer := table.TextRenderer{
 		Color:     r.color,
 		Thousands: r.thousands,
 		Round:     r.digits,
+		Rounding:  rounding,
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
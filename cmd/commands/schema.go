@@ -0,0 +1,118 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/sboehler/knut/cmd/commands/portfolio"
+)
+
+// outputSchemas maps each command with a JSON output to a value of the
+// type it encodes, and the version of its schema.
+var outputSchemas = map[string]struct {
+	value   any
+	version int
+}{
+	"returns": {portfolio.JSONReturns{}, portfolio.SchemaVersion},
+}
+
+// CreateSchemaCommand creates the command.
+func CreateSchemaCommand() *cobra.Command {
+	var r schemaRunner
+	validArgs := maps.Keys(outputSchemas)
+	slices.Sort(validArgs)
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "print the JSON schema of a command's output",
+		Long:  fmt.Sprintf(`Print the JSON schema of the JSON output of the given command (one of: %s).`, strings.Join(validArgs, ", ")),
+
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: validArgs,
+
+		Run: r.run,
+	}
+}
+
+type schemaRunner struct{}
+
+func (r *schemaRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *schemaRunner) execute(cmd *cobra.Command, args []string) error {
+	s := outputSchemas[args[0]]
+	res := jsonSchema(reflect.TypeOf(s.value))
+	res["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	res["schemaVersion"] = s.version
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+// jsonSchema derives a JSON schema from the given type, using the same
+// field names as encoding/json.
+func jsonSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Pointer:
+		return jsonSchema(t.Elem())
+	case reflect.Struct:
+		props := make(map[string]any)
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = jsonSchema(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]any{"type": "object", "properties": props, "required": required}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	}
+	return map[string]any{}
+}
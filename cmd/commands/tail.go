@@ -0,0 +1,251 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sourcegraph/conc/pool"
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/common/cpr"
+	"github.com/sboehler/knut/lib/common/regex"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/journal/printer"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/syntax"
+)
+
+// CreateTailCommand creates the command.
+func CreateTailCommand() *cobra.Command {
+	var r tailRunner
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "print transactions as they are added to the journal",
+		Long: `Watch the journal and its included files, and print the transactions added since the
+previous parse whenever a file changes. With --account, each transaction is followed by its
+effect on the matching accounts and their resulting position. Press Ctrl-C to exit.`,
+
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		Run: r.run,
+	}
+	r.setupFlags(cmd)
+	return cmd
+}
+
+type tailRunner struct {
+	account  flags.RegexFlag
+	interval time.Duration
+}
+
+func (r *tailRunner) setupFlags(c *cobra.Command) {
+	c.Flags().Var(&r.account, "account", "show the effect on accounts matching the regex")
+	c.Flags().DurationVar(&r.interval, "interval", time.Second, "how often to check the files for changes")
+}
+
+func (r *tailRunner) run(cmd *cobra.Command, args []string) {
+	if err := r.execute(cmd, args); err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		os.Exit(1)
+	}
+}
+
+func (r *tailRunner) execute(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	prev, err := loadTail(ctx, args[0], r.account.Regex())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "watching %d file(s) with %d transactions\n", len(prev.files), len(prev.transactions))
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if !prev.changed() {
+			continue
+		}
+		next, err := loadTail(ctx, args[0], r.account.Regex())
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintln(cmd.ErrOrStderr(), err)
+			// Wait for the next change before parsing again.
+			prev.files = modTimes(prev.files)
+			continue
+		}
+		if err := writeTail(cmd.OutOrStdout(), next.added(prev)); err != nil {
+			return err
+		}
+		prev = next
+	}
+}
+
+// tailSnapshot holds the result of parsing the journal.
+type tailSnapshot struct {
+	files        map[string]time.Time
+	transactions []tailTransaction
+}
+
+type tailTransaction struct {
+	text string
+
+	// effect holds the change of the positions in the matching accounts,
+	// and position the positions after the transaction, in journal order.
+	effect, position map[tailPosition]decimal.Decimal
+}
+
+// tailPosition identifies a position by name, as the registry is not shared
+// between parses.
+type tailPosition struct {
+	account, commodity string
+}
+
+// loadTail parses the journal at the given path, recording the effect of
+// each transaction on the accounts matching the regexes.
+func loadTail(ctx context.Context, path string, accounts regex.Regexes) (*tailSnapshot, error) {
+	files := make(map[string]time.Time)
+	syntaxCh, worker1 := syntax.ParseFileRecursively(path)
+	watchCh, worker2 := cpr.Produce(func(ctx context.Context, ch chan<- syntax.File) error {
+		return cpr.ForEach(ctx, syntaxCh, func(f syntax.File) error {
+			files[f.Path] = time.Time{}
+			return cpr.Push(ctx, ch, f)
+		})
+	})
+	modelCh, worker3 := model.FromStream(registry.New(), watchCh)
+	journalCh, worker4 := journal.FromModelStream(modelCh)
+	p := pool.New().WithErrors().WithFirstError().WithContext(ctx)
+	p.Go(worker1)
+	p.Go(worker2)
+	p.Go(worker3)
+	p.Go(worker4)
+	if err := p.Wait(); err != nil {
+		return nil, err
+	}
+	b := <-journalCh
+	s := &tailSnapshot{files: modTimes(files)}
+	positions := make(map[tailPosition]decimal.Decimal)
+	for _, d := range b.Build().Days {
+		for _, t := range d.Transactions {
+			var buf bytes.Buffer
+			if _, err := printer.New(&buf).PrintDirective(t); err != nil {
+				return nil, err
+			}
+			tt := tailTransaction{
+				text:     buf.String(),
+				effect:   make(map[tailPosition]decimal.Decimal),
+				position: make(map[tailPosition]decimal.Decimal),
+			}
+			for _, p := range t.Postings {
+				if len(accounts) == 0 || !accounts.MatchString(p.Account.Name()) {
+					continue
+				}
+				key := tailPosition{p.Account.Name(), p.Commodity.Name()}
+				tt.effect[key] = tt.effect[key].Add(p.Quantity)
+				positions[key] = positions[key].Add(p.Quantity)
+			}
+			for key := range tt.effect {
+				tt.position[key] = positions[key]
+			}
+			s.transactions = append(s.transactions, tt)
+		}
+	}
+	return s, nil
+}
+
+// modTimes returns the current modification times of the given files. Files
+// which cannot be accessed get the zero time.
+func modTimes(files map[string]time.Time) map[string]time.Time {
+	res := make(map[string]time.Time, len(files))
+	for path := range files {
+		if fi, err := os.Stat(path); err == nil {
+			res[path] = fi.ModTime()
+		} else {
+			res[path] = time.Time{}
+		}
+	}
+	return res
+}
+
+func (s *tailSnapshot) changed() bool {
+	for path, t := range modTimes(s.files) {
+		if !t.Equal(s.files[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// added returns the transactions of s which are not in prev, in journal
+// order. Identical transactions are matched by their count.
+func (s *tailSnapshot) added(prev *tailSnapshot) []tailTransaction {
+	counts := make(map[string]int)
+	for _, t := range prev.transactions {
+		counts[t.text]++
+	}
+	var res []tailTransaction
+	for _, t := range s.transactions {
+		if counts[t.text] > 0 {
+			counts[t.text]--
+			continue
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+func writeTail(w io.Writer, ts []tailTransaction) error {
+	for _, t := range ts {
+		if _, err := io.WriteString(w, t.text); err != nil {
+			return err
+		}
+		var keys []tailPosition
+		for key := range t.effect {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].account != keys[j].account {
+				return keys[i].account < keys[j].account
+			}
+			return keys[i].commodity < keys[j].commodity
+		})
+		for _, key := range keys {
+			if _, err := fmt.Fprintf(w, "  => %s %s %s (position: %s %s)\n", key.account, t.effect[key], key.commodity, t.position[key], key.commodity); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sboehler/knut/lib/common/regex"
+)
+
+func writeTailFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadTailWatchesAllFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTailFiles(t, dir, map[string]string{
+		"main.knut":     "include \"accounts.knut\"\ninclude \"settings.knut\"\n",
+		"accounts.knut": "2020-01-01 open Assets:Bank\n2020-01-01 open Equity:Opening\n",
+		"settings.knut": "",
+	})
+
+	s, err := loadTail(context.Background(), filepath.Join(dir, "main.knut"), nil)
+	if err != nil {
+		t.Fatalf("loadTail(): unexpected error %v", err)
+	}
+
+	var got []string
+	for path := range s.files {
+		got = append(got, filepath.Base(path))
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"accounts.knut", "main.knut", "settings.knut"}, got); diff != "" {
+		t.Fatalf("files: unexpected diff (-want/+got):\n%s", diff)
+	}
+}
+
+func TestTailAdded(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.knut")
+	accounts := regex.Regexes{regexp.MustCompile("Assets:Bank")}
+	journal := `2020-01-01 open Assets:Bank
+2020-01-01 open Equity:Opening
+
+2020-01-01 "Deposit"
+Equity:Opening Assets:Bank 100 CHF
+
+2020-01-10 "Deposit"
+Equity:Opening Assets:Bank 50 CHF
+`
+	writeTailFiles(t, dir, map[string]string{"main.knut": journal})
+	prev, err := loadTail(context.Background(), path, accounts)
+	if err != nil {
+		t.Fatalf("loadTail(): unexpected error %v", err)
+	}
+	writeTailFiles(t, dir, map[string]string{"main.knut": journal + `
+2020-01-05 "Withdrawal"
+Assets:Bank Equity:Opening 30 CHF
+`})
+	next, err := loadTail(context.Background(), path, accounts)
+	if err != nil {
+		t.Fatalf("loadTail(): unexpected error %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := writeTail(&got, next.added(prev)); err != nil {
+		t.Fatalf("writeTail(): unexpected error %v", err)
+	}
+
+	want := `2020-01-05 "Withdrawal"
+Assets:Bank Equity:Opening         30 CHF
+  => Assets:Bank -30 CHF (position: 70 CHF)
+
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Fatalf("unexpected diff (-want/+got):\n%s", diff)
+	}
+}
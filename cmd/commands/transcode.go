@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/sboehler/knut/cmd/diagnostics"
 	"github.com/sboehler/knut/cmd/flags"
 	"github.com/sboehler/knut/lib/journal"
 	"github.com/sboehler/knut/lib/journal/beancount"
@@ -69,18 +70,20 @@ func (r *transcodeRunner) execute(cmd *cobra.Command, args []string) (errors err
 		valuation *model.Commodity
 		err       error
 	)
-	if valuation, err = r.valuation.Value(reg); err != nil {
-		return err
-	}
 	b, err := journal.FromPath(cmd.Context(), reg, args[0])
 	if err != nil {
 		return err
 	}
+	diagnostics.WarnAll(cmd, reg.Options().Unknown())
+	if valuation, err = r.valuation.ValueOrDefault(reg); err != nil {
+		return err
+	}
 	j := b.Build()
 	err = j.Process(
 		journal.Sort(),
-		journal.ComputePrices(valuation),
 		check.Check(),
+		journal.ApplySplits(reg),
+		journal.ComputePrices(valuation),
 		journal.Valuate(reg, valuation),
 	)
 	if err != nil {
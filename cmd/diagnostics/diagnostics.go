@@ -0,0 +1,75 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diagnostics collects warnings emitted by commands.
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// Collector counts the warnings emitted during a command run.
+type Collector struct {
+	FailOnWarning bool
+
+	mutex    sync.Mutex
+	warnings int
+}
+
+// Warnf writes a warning to w and records it.
+func (c *Collector) Warnf(w io.Writer, format string, args ...any) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.warnings++
+	fmt.Fprintf(w, "warning: "+format+"\n", args...)
+}
+
+// Err returns an error if warnings have been emitted and FailOnWarning is set.
+func (c *Collector) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.FailOnWarning && c.warnings > 0 {
+		return fmt.Errorf("%d warning(s) emitted and --fail-on-warning is set", c.warnings)
+	}
+	return nil
+}
+
+type key struct{}
+
+// NewContext returns a context carrying the collector.
+func NewContext(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, key{}, c)
+}
+
+// Warnf emits a warning through the collector in the command's context,
+// writing it to the command's stderr.
+func Warnf(cmd *cobra.Command, format string, args ...any) {
+	c, ok := cmd.Context().Value(key{}).(*Collector)
+	if !ok {
+		c = new(Collector)
+	}
+	c.Warnf(cmd.ErrOrStderr(), format, args...)
+}
+
+// WarnAll emits each of the given errors as a warning.
+func WarnAll(cmd *cobra.Command, errs []error) {
+	for _, err := range errs {
+		Warnf(cmd, "%v", err)
+	}
+}
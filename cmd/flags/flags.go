@@ -105,8 +105,9 @@ func (rf *RegexFlag) Regex() regex.Regexes {
 
 // IntervalFlags manages multiple flags to determine a time period.
 type IntervalFlags struct {
-	def   date.Interval
-	flags [6]bool
+	def      date.Interval
+	flags    [6]bool
+	interval intervalFlag
 }
 
 // Setup configures the flags.
@@ -117,12 +118,16 @@ func (pf *IntervalFlags) Setup(cmd *cobra.Command, def date.Interval) {
 	cmd.Flags().BoolVar(&pf.flags[date.Monthly], "months", false, "months")
 	cmd.Flags().BoolVar(&pf.flags[date.Quarterly], "quarters", false, "quarters")
 	cmd.Flags().BoolVar(&pf.flags[date.Yearly], "years", false, "years")
-	cmd.MarkFlagsMutuallyExclusive("days", "weeks", "months", "quarters", "years")
+	cmd.Flags().Var(&pf.interval, "interval", "the interval (once, daily, weekly, monthly, quarterly, yearly or none)")
+	cmd.MarkFlagsMutuallyExclusive("days", "weeks", "months", "quarters", "years", "interval")
 	pf.def = def
 }
 
 // Value returns the period.
 func (pf IntervalFlags) Value() date.Interval {
+	if pf.interval.set {
+		return pf.interval.value
+	}
 	for i, val := range pf.flags {
 		if val {
 			return date.Interval(i)
@@ -131,6 +136,66 @@ func (pf IntervalFlags) Value() date.Interval {
 	return pf.def
 }
 
+// ValueOrDefault returns the interval, or the default interval declared
+// by the journal options if no flag is set.
+func (pf IntervalFlags) ValueOrDefault(reg *model.Registry) date.Interval {
+	if i, ok := reg.Options().Interval(); ok && !pf.isSet() {
+		return i
+	}
+	return pf.Value()
+}
+
+func (pf IntervalFlags) isSet() bool {
+	for _, val := range pf.flags {
+		if val {
+			return true
+		}
+	}
+	return pf.interval.set
+}
+
+// None returns whether --interval none was given, requesting a single
+// snapshot which carries the balances before its start forward.
+func (pf IntervalFlags) None() bool {
+	return pf.interval.none
+}
+
+type intervalFlag struct {
+	value     date.Interval
+	set, none bool
+}
+
+var _ pflag.Value = (*intervalFlag)(nil)
+
+func (f intervalFlag) String() string {
+	if f.none {
+		return "none"
+	}
+	if f.set {
+		return f.value.String()
+	}
+	return ""
+}
+
+// Set implements pflag.Value.
+func (f *intervalFlag) Set(v string) error {
+	if v == "none" {
+		*f = intervalFlag{value: date.Once, set: true, none: true}
+		return nil
+	}
+	interval, err := date.ParseInterval(v)
+	if err != nil {
+		return err
+	}
+	*f = intervalFlag{value: interval, set: true}
+	return nil
+}
+
+// Type implements pflag.Value.
+func (f intervalFlag) Type() string {
+	return "<interval>"
+}
+
 type PeriodFlag struct {
 	start, end DateFlag
 }
@@ -146,6 +211,37 @@ func (pf *PeriodFlag) Value() date.Period {
 	return date.Period{Start: pf.start.Value(), End: pf.end.Value()}
 }
 
+// RangeFlag manages a flag naming a period preset.
+type RangeFlag struct {
+	name   string
+	period date.Period
+}
+
+// Set implements pflag.Value.
+func (rf *RangeFlag) Set(v string) error {
+	p, err := date.Range(v, date.Today())
+	if err != nil {
+		return err
+	}
+	rf.name, rf.period = v, p
+	return nil
+}
+
+// Type implements pflag.Value.
+func (rf RangeFlag) Type() string {
+	return "<range>"
+}
+
+// String implements pflag.Value.
+func (rf RangeFlag) String() string {
+	return rf.name
+}
+
+// Value returns the period, if a preset has been given.
+func (rf RangeFlag) Value() (date.Period, bool) {
+	return rf.period, rf.name != ""
+}
+
 // MappingFlag manages a flag of type -c1,<regex>.
 type MappingFlag struct {
 	m account.Mapping
@@ -235,6 +331,16 @@ func (cf CommodityFlag) Value(reg *model.Registry) (*model.Commodity, error) {
 	return nil, nil
 }
 
+// ValueOrDefault returns the commodity, or the default valuation commodity
+// declared by the journal options if the flag is not set. It must be
+// called after the journal has been parsed.
+func (cf CommodityFlag) ValueOrDefault(reg *model.Registry) (*model.Commodity, error) {
+	if c, ok := reg.Options().Valuation(); ok && cf.val == "" {
+		return c, nil
+	}
+	return cf.Value(reg)
+}
+
 // AccountFlag manages a flag to parse a commodity.
 type AccountFlag struct {
 	val string
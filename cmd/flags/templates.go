@@ -1,22 +1,37 @@
 package flags
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/model"
 	"github.com/spf13/cobra"
 )
 
 type Multiperiod struct {
 	period   PeriodFlag
+	rng      RangeFlag
 	last     int
 	interval IntervalFlags
 }
 
 func (mp *Multiperiod) Setup(cmd *cobra.Command) {
 	mp.period.Setup(cmd, date.Period{End: date.Today()})
+	cmd.Flags().Var(&mp.rng, "range", fmt.Sprintf("preset period relative to today (%s)", strings.Join(date.Ranges, ", ")))
+	cmd.MarkFlagsMutuallyExclusive("range", "from")
+	cmd.MarkFlagsMutuallyExclusive("range", "to")
 	cmd.Flags().IntVar(&mp.last, "last", 0, "last n periods")
 	mp.interval.Setup(cmd, date.Once)
 }
 
-func (mp *Multiperiod) Partition(clip date.Period) date.Partition {
-	return date.NewPartition(mp.period.Value().Clip(clip), mp.interval.Value(), mp.last)
+func (mp *Multiperiod) Partition(reg *model.Registry, clip date.Period) date.Partition {
+	period := mp.period.Value()
+	if p, ok := mp.rng.Value(); ok {
+		period = p
+	}
+	if mp.interval.None() {
+		return date.NewSnapshot(period.Clip(clip))
+	}
+	return date.NewFiscalPartition(period.Clip(clip), mp.interval.ValueOrDefault(reg), mp.last, reg.Options().FiscalYearStart())
 }
@@ -54,7 +54,7 @@ using the default options and saving it to CSV. This importer will parse the una
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Beschreibung,Gutschrift CHF,Belastung CHF"))
 }
 
 type runner struct {
@@ -92,7 +92,7 @@ func (r *runner) run(cmd *cobra.Command, args []string) error {
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, j.Build())
+	return importer.Print(cmd, out, j.Build())
 }
 
 type parser struct {
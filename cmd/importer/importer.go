@@ -1,14 +1,98 @@
 package importer
 
-import "github.com/spf13/cobra"
+import (
+	"bytes"
+	"io"
+	"sort"
 
-var importers []func() *cobra.Command
+	"github.com/spf13/cobra"
 
-// RegisterImporter registers an importer constructor.
-func RegisterImporter(f func() *cobra.Command) {
-	importers = append(importers, f)
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/lib/journal"
+)
+
+// Info describes a registered importer.
+type Info struct {
+	// Name is the name of the import subcommand.
+	Name string
+	// Description is the one-line description of the importer.
+	Description string
+	// Create creates the import subcommand.
+	Create func() *cobra.Command
+	// Detect reports whether the importer recognizes a file by its
+	// beginning. It is nil if the importer cannot be detected.
+	Detect Detector
+}
+
+// Detector reports whether a file is in an importer's format, given
+// the beginning of the file.
+type Detector func(head []byte) bool
+
+var importers []Info
+
+// RegisterImporter registers an importer constructor, along with the name
+// and the short description of the command it creates, and a detector for
+// its files, which may be nil.
+func RegisterImporter(f func() *cobra.Command, detect Detector) {
+	cmd := f()
+	importers = append(importers, Info{
+		Name:        cmd.Name(),
+		Description: cmd.Short,
+		Create:      f,
+		Detect:      detect,
+	})
+}
+
+// HasSignature returns a detector which recognizes files containing the
+// given signature, typically a part of the header line, near their start.
+func HasSignature(signature string) Detector {
+	return func(head []byte) bool {
+		return bytes.Contains(head, []byte(signature))
+	}
+}
+
+// DetectLength is the number of bytes passed to detectors.
+const DetectLength = 4096
+
+// Detect returns the importers which recognize the given beginning of
+// a file, sorted by name.
+func Detect(head []byte) []Info {
+	var res []Info
+	for _, info := range Importers() {
+		if info.Detect != nil && info.Detect(head) {
+			res = append(res, info)
+		}
+	}
+	return res
 }
 
 func GetImporters() []func() *cobra.Command {
-	return importers
+	var res []func() *cobra.Command
+	for _, info := range importers {
+		res = append(res, info.Create)
+	}
+	return res
+}
+
+// Importers returns the registered importers, sorted by name.
+func Importers() []Info {
+	res := append([]Info(nil), importers...)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// SetupCommodityFlag adds a --commodity flag with the given default, for
+// statements which do not specify their currency.
+func SetupCommodityFlag(cmd *cobra.Command, f *flags.CommodityFlag, def string) {
+	f.Set(def)
+	cmd.Flags().Var(f, "commodity", "the commodity of the statement")
+}
+
+// Print prints the imported journal. If the --mark-tbd flag of the import
+// command is set, postings of the TBD account are marked with a comment.
+func Print(cmd *cobra.Command, w io.Writer, j *journal.Journal) error {
+	mark, _ := cmd.Flags().GetBool("mark-tbd")
+	return journal.Printer{MarkTBD: mark}.Print(w, j)
 }
@@ -0,0 +1,72 @@
+package importer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sboehler/knut/cmd/importer"
+	_ "github.com/sboehler/knut/cmd/importer/cumulus"
+	_ "github.com/sboehler/knut/cmd/importer/interactivebrokers"
+	_ "github.com/sboehler/knut/cmd/importer/n26"
+	_ "github.com/sboehler/knut/cmd/importer/positions"
+	_ "github.com/sboehler/knut/cmd/importer/postfinance"
+	_ "github.com/sboehler/knut/cmd/importer/revolut"
+	_ "github.com/sboehler/knut/cmd/importer/revolut2"
+	_ "github.com/sboehler/knut/cmd/importer/supercard"
+	_ "github.com/sboehler/knut/cmd/importer/swisscard"
+	_ "github.com/sboehler/knut/cmd/importer/swisscard2"
+	_ "github.com/sboehler/knut/cmd/importer/swisscard3"
+	_ "github.com/sboehler/knut/cmd/importer/swissquote"
+	_ "github.com/sboehler/knut/cmd/importer/ubsaccount"
+	_ "github.com/sboehler/knut/cmd/importer/ubscard"
+	_ "github.com/sboehler/knut/cmd/importer/viac"
+	_ "github.com/sboehler/knut/cmd/importer/wise"
+)
+
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		"cumulus":            "ch.cumulus",
+		"interactivebrokers": "us.interactivebrokers",
+		"n26":                "n26",
+		"positions":          "positions",
+		"postfinance":        "ch.postfinance",
+		"revolut":            "revolut",
+		"revolut2":           "revolut2",
+		"supercard":          "ch.supercard",
+		"swisscard":          "ch.swisscard",
+		"swisscard2":         "ch.swisscard2",
+		"swisscard3":         "ch.swisscard3",
+		"swissquote":         "ch.swissquote",
+		"ubsaccount":         "ch.ubs.account",
+		"ubscard":            "ch.ubs.card",
+		"viac":               "ch.viac",
+		"wise":               "com.wise",
+	}
+	for dir, want := range tests {
+		files, err := filepath.Glob(filepath.Join(dir, "testdata", "*.input"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			t.Run(file, func(t *testing.T) {
+				content, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(content) > importer.DetectLength {
+					content = content[:importer.DetectLength]
+				}
+				var got []string
+				for _, info := range importer.Detect(content) {
+					got = append(got, info.Name)
+				}
+				if diff := cmp.Diff([]string{want}, got); diff != "" {
+					t.Errorf("Detect(%s): unexpected diff (+got/-want):\n%s", file, diff)
+				}
+			})
+		}
+	}
+}
@@ -52,7 +52,7 @@ func CreateCmd() *cobra.Command {
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Statement,Header,Field Name,Field Value"))
 }
 
 type runner struct {
@@ -111,7 +111,7 @@ func (r *runner) run(cmd *cobra.Command, args []string) error {
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, p.builder.Build())
+	return importer.Print(cmd, out, p.builder.Build())
 }
 
 type parser struct {
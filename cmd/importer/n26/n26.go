@@ -55,11 +55,17 @@ func CreateCmd() *cobra.Command {
 }
 
 type runner struct {
-	accountFlag flags.AccountFlag
+	accountFlag   flags.AccountFlag
+	commodityFlag flags.CommodityFlag
+	mergeBy       string
+	merge         string
 }
 
 func (r *runner) setupFlags(cmd *cobra.Command) {
 	cmd.Flags().VarP(&r.accountFlag, "account", "a", "account name")
+	importer.SetupCommodityFlag(cmd, &r.commodityFlag, "EUR")
+	cmd.Flags().StringVar(&r.mergeBy, "merge-by", "", "merge rows sharing a non-empty value in the given column (e.g. \"Payment reference\") into one transaction")
+	cmd.Flags().StringVar(&r.merge, "merge", "booked", "how to merge rows with --merge-by: booked takes the last row, sum adds up the amounts")
 	cmd.MarkFlagRequired("account")
 }
 
@@ -76,6 +82,9 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 		reg    = registry.New()
 		err    error
 	)
+	if r.merge != "booked" && r.merge != "sum" {
+		return fmt.Errorf("invalid merge strategy %q, want booked or sum", r.merge)
+	}
 	if reader, err = flags.OpenFile(args[0]); err != nil {
 		return err
 	}
@@ -83,28 +92,50 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 		registry: reg,
 		reader:   csv.NewReader(utfbom.SkipOnly(reader)),
 		builder:  journal.New(),
+		mergeBy:  r.mergeBy,
+		sum:      r.merge == "sum",
 	}
 	if p.account, err = r.accountFlag.Value(reg.Accounts()); err != nil {
 		return err
 	}
+	if p.commodity, err = r.commodityFlag.Value(reg); err != nil {
+		return err
+	}
 	if err = p.parse(); err != nil {
 		return err
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, p.builder.Build())
+	return importer.Print(cmd, out, p.builder.Build())
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature(`"Date","Payee","Account number","Transaction type","Payment reference"`))
 }
 
 // Parser is a parser for account statements
 type Parser struct {
-	registry *model.Registry
-	reader   *csv.Reader
-	account  *model.Account
-	builder  *journal.Builder
+	registry  *model.Registry
+	reader    *csv.Reader
+	account   *model.Account
+	commodity *model.Commodity
+	builder   *journal.Builder
+
+	// mergeBy is the name of the column by which rows are merged. If sum is
+	// set, the amounts of merged rows are added up, otherwise the last row,
+	// which is the booked one, replaces the earlier ones.
+	mergeBy     string
+	sum         bool
+	mergeColumn int
+	bookings    []*booking
+	references  map[string]*booking
+}
+
+// booking is a transaction which has not yet been added to the journal.
+type booking struct {
+	date        time.Time
+	description string
+	quantity    decimal.Decimal
 }
 
 func (p *Parser) parse() error {
@@ -112,8 +143,9 @@ func (p *Parser) parse() error {
 	p.reader.TrimLeadingSpace = true
 	p.reader.Comma = ','
 	p.reader.FieldsPerRecord = 9
+	p.references = make(map[string]*booking)
 
-	if err := p.skipHeader(); err != nil {
+	if err := p.readHeader(); err != nil {
 		return err
 	}
 	for {
@@ -122,14 +154,40 @@ func (p *Parser) parse() error {
 			return err
 		}
 		if !ok {
-			return nil
+			break
 		}
 	}
+	for _, b := range p.bookings {
+		p.builder.Add(transaction.Builder{
+			Date:        b.date,
+			Description: b.description,
+			Postings: posting.Builder{
+				Credit:    p.registry.Accounts().TBDAccount(),
+				Debit:     p.account,
+				Commodity: p.commodity,
+				Quantity:  b.quantity,
+			}.Build(),
+		}.Build())
+	}
+	return nil
 }
 
-func (p *Parser) skipHeader() error {
-	_, err := p.reader.Read()
-	return err
+func (p *Parser) readHeader() error {
+	rec, err := p.reader.Read()
+	if err != nil {
+		return err
+	}
+	p.mergeColumn = -1
+	if p.mergeBy == "" {
+		return nil
+	}
+	for i, name := range rec {
+		if name == p.mergeBy {
+			p.mergeColumn = i
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown column %q, want one of %q", p.mergeBy, rec)
 }
 
 type bookingField int
@@ -162,15 +220,29 @@ func (p *Parser) readBookingLine() (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	p.builder.Add(transaction.Builder{
-		Date:        date,
-		Description: strings.TrimSpace(rec[bfPayee]),
-		Postings: posting.Builder{
-			Credit:    p.registry.Accounts().TBDAccount(),
-			Debit:     p.account,
-			Commodity: p.registry.Commodities().MustGet("EUR"),
-			Quantity:  quantity,
-		}.Build(),
-	}.Build())
+	var ref string
+	if p.mergeColumn >= 0 {
+		ref = rec[p.mergeColumn]
+	}
+	if b, ok := p.references[ref]; ok && ref != "" {
+		// Later rows are the booked ones, so their date and payee win.
+		b.date = date
+		b.description = strings.TrimSpace(rec[bfPayee])
+		if p.sum {
+			b.quantity = b.quantity.Add(quantity)
+		} else {
+			b.quantity = quantity
+		}
+		return true, nil
+	}
+	b := &booking{
+		date:        date,
+		description: strings.TrimSpace(rec[bfPayee]),
+		quantity:    quantity,
+	}
+	p.bookings = append(p.bookings, b)
+	if ref != "" {
+		p.references[ref] = b
+	}
 	return true, nil
 }
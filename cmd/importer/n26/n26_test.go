@@ -28,3 +28,24 @@ func TestGolden(t *testing.T) {
 
 	goldie.New(t).Assert(t, "example1", got)
 }
+
+func TestGoldenMergeBy(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateCmd(), "--account", "Liabilities:CreditCard", "--merge-by", "Payment reference", "--merge", "sum", "testdata/example2.input")
+
+	goldie.New(t).Assert(t, "example2", got)
+}
+
+func TestGoldenMergeBooked(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateCmd(), "--account", "Liabilities:CreditCard", "--merge-by", "Payment reference", "testdata/example4.input")
+
+	goldie.New(t).Assert(t, "example4", got)
+}
+
+func TestGoldenCommodity(t *testing.T) {
+
+	got := cmdtest.Run(t, CreateCmd(), "--account", "Liabilities:CreditCard", "--commodity", "USD", "testdata/example1.input")
+
+	goldie.New(t).Assert(t, "example3", got)
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package positions
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/sboehler/knut/cmd/flags"
+	"github.com/sboehler/knut/cmd/importer"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/registry"
+)
+
+// CreateCmd creates the command.
+func CreateCmd() *cobra.Command {
+	var r runner
+	cmd := &cobra.Command{
+		Use:   "positions",
+		Short: "Import broker position snapshots as balance assertions",
+		Long: `Import a CSV file with the columns "Symbol" and "Quantity" and emit a balance assertion
+for every position at the given date.`,
+
+		RunE: r.run,
+	}
+	r.setupFlags(cmd)
+	return cmd
+}
+
+func init() {
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Symbol,Quantity"))
+}
+
+type runner struct {
+	account flags.AccountFlag
+	date    flags.DateFlag
+	symbols map[string]string
+}
+
+func (r *runner) setupFlags(cmd *cobra.Command) {
+	cmd.Flags().VarP(&r.account, "account", "a", "account name")
+	cmd.Flags().Var(&r.date, "date", "date of the snapshot")
+	cmd.Flags().StringToStringVar(&r.symbols, "symbol", nil, "map a broker symbol to a commodity (<symbol>=<commodity>)")
+	cmd.MarkFlagRequired("account")
+	cmd.MarkFlagRequired("date")
+}
+
+func (r *runner) run(cmd *cobra.Command, args []string) error {
+	var (
+		reg = registry.New()
+		f   *bufio.Reader
+		err error
+	)
+	builder := journal.New()
+	for _, path := range args {
+		if f, err = flags.OpenFile(path); err != nil {
+			return err
+		}
+		p := parser{
+			registry: reg,
+			reader:   csv.NewReader(f),
+			builder:  builder,
+			date:     r.date.Value(),
+			symbols:  r.symbols,
+		}
+		if p.account, err = r.account.Value(reg.Accounts()); err != nil {
+			return err
+		}
+		if err = p.parse(); err != nil {
+			return err
+		}
+	}
+	out := bufio.NewWriter(cmd.OutOrStdout())
+	defer out.Flush()
+	return importer.Print(cmd, out, builder.Build())
+}
+
+type parser struct {
+	registry *model.Registry
+	reader   *csv.Reader
+	account  *model.Account
+	builder  *journal.Builder
+	date     time.Time
+	symbols  map[string]string
+}
+
+type column int
+
+const (
+	cSymbol column = iota
+	cQuantity
+)
+
+func (p *parser) parse() error {
+	p.reader.TrimLeadingSpace = true
+	p.reader.Comma = ','
+	p.reader.FieldsPerRecord = 2
+
+	if err := p.parseHeader(); err != nil {
+		return err
+	}
+	var balances []model.Balance
+	for {
+		r, err := p.reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		b, err := p.parsePosition(r)
+		if err != nil {
+			return err
+		}
+		balances = append(balances, b)
+	}
+	if len(balances) > 0 {
+		p.builder.Add(&model.Assertion{
+			Date:     p.date,
+			Balances: balances,
+		})
+	}
+	return nil
+}
+
+func (p *parser) parseHeader() error {
+	r, err := p.reader.Read()
+	if err != nil {
+		return err
+	}
+	header := []string{"Symbol", "Quantity"}
+	for i := range header {
+		if r[i] != header[i] {
+			return fmt.Errorf("invalid header: %v", r)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parsePosition(r []string) (model.Balance, error) {
+	symbol := strings.TrimSpace(r[cSymbol])
+	if name, ok := p.symbols[symbol]; ok {
+		symbol = name
+	}
+	c, err := p.registry.Commodities().Get(symbol)
+	if err != nil {
+		return model.Balance{}, fmt.Errorf("invalid commodity in row %v: %v", r, err)
+	}
+	quantity, err := decimal.NewFromString(strings.ReplaceAll(r[cQuantity], "'", ""))
+	if err != nil {
+		return model.Balance{}, fmt.Errorf("invalid quantity in row %v: %v", r, err)
+	}
+	return model.Balance{
+		Account:   p.account,
+		Commodity: c,
+		Quantity:  quantity,
+	}, nil
+}
@@ -54,11 +54,13 @@ func CreateCmd() *cobra.Command {
 }
 
 type runner struct {
-	accountFlag flags.AccountFlag
+	accountFlag   flags.AccountFlag
+	commodityFlag flags.CommodityFlag
 }
 
 func (r *runner) setupFlags(cmd *cobra.Command) {
 	cmd.Flags().VarP(&r.accountFlag, "account", "a", "account name")
+	importer.SetupCommodityFlag(cmd, &r.commodityFlag, "CHF")
 	cmd.MarkFlagRequired("account")
 }
 
@@ -86,16 +88,19 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 	if p.account, err = r.accountFlag.Value(reg.Accounts()); err != nil {
 		return err
 	}
+	if p.currency, err = r.commodityFlag.Value(reg); err != nil {
+		return err
+	}
 	if err = p.parse(); err != nil {
 		return err
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, p.builder.Build())
+	return importer.Print(cmd, out, p.builder.Build())
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Buchungsart:;"))
 }
 
 // Parser is a parser for account statements
@@ -123,8 +128,6 @@ func (p *Parser) parse() error {
 		if p.currency, err = p.registry.Commodities().Get(sym); err != nil {
 			return err
 		}
-	} else {
-		p.currency = p.registry.Commodities().MustGet("CHF")
 	}
 	for {
 		ok, err := p.readBookingLine()
@@ -52,7 +52,7 @@ func CreateCmd() *cobra.Command {
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Completed Date;Reference;Paid Out"))
 }
 
 type runner struct {
@@ -86,7 +86,7 @@ func (r *runner) run(cmd *cobra.Command, args []string) error {
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, p.builder.Build())
+	return importer.Print(cmd, out, p.builder.Build())
 }
 
 type parser struct {
@@ -49,7 +49,7 @@ func CreateCmd() *cobra.Command {
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance"))
 }
 
 type runner struct {
@@ -91,7 +91,7 @@ func (r *runner) run(cmd *cobra.Command, args []string) error {
 	}
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, builder.Build())
+	return importer.Print(cmd, out, builder.Build())
 }
 
 type parser struct {
@@ -50,7 +50,7 @@ func CreateCmd() *cobra.Command {
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature("Transaktionsdatum,Beschreibung,Händler,Kartennummer"))
 }
 
 type runner struct {
@@ -84,7 +84,7 @@ func (r *runner) run(cmd *cobra.Command, args []string) error {
 	}
 	w := bufio.NewWriter(cmd.OutOrStdout())
 	defer w.Flush()
-	return journal.Print(w, p.builder.Build())
+	return importer.Print(cmd, w, p.builder.Build())
 }
 
 type parser struct {
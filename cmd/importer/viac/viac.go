@@ -47,7 +47,7 @@ func CreateCmd() *cobra.Command {
 }
 
 func init() {
-	importer.RegisterImporter(CreateCmd)
+	importer.RegisterImporter(CreateCmd, importer.HasSignature(`"dailyWealth"`))
 }
 
 func (r *runner) setupFlags(cmd *cobra.Command) {
@@ -104,7 +104,7 @@ func (r *runner) run(cmd *cobra.Command, args []string) error {
 
 	out := bufio.NewWriter(cmd.OutOrStdout())
 	defer out.Flush()
-	return journal.Print(out, j.Build())
+	return importer.Print(cmd, out, j.Build())
 }
 
 type response struct {
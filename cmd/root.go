@@ -16,29 +16,51 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/sboehler/knut/cmd/commands"
+	"github.com/sboehler/knut/cmd/diagnostics"
 
 	"github.com/spf13/cobra"
 )
 
 // CreateCmd creates the command.
 func CreateCmd(version string) *cobra.Command {
+	var diag diagnostics.Collector
 	c := &cobra.Command{
 		Use:     "knut",
 		Short:   "knut is a plain text accounting tool",
 		Long:    `knut is a plain text accounting tool for tracking personal finances and investments.`,
 		Version: version,
+
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmd.SetContext(diagnostics.NewContext(cmd.Context(), &diag))
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if err := diag.Err(); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+				os.Exit(1)
+			}
+		},
 	}
+	c.PersistentFlags().BoolVar(&diag.FailOnWarning, "fail-on-warning", false, "exit with an error if any warning has been emitted")
 	c.AddCommand(commands.CreateBalanceCommand())
 	c.AddCommand(commands.CreateCheckCommand())
 	c.AddCommand(commands.CreateCompletionCommand(c))
+	c.AddCommand(commands.CreateConsolidateCommand())
+	c.AddCommand(commands.CreateDiagnoseCommand())
+	c.AddCommand(commands.CreateDigestCommand())
 	c.AddCommand(commands.CreateFormatCommand())
 	c.AddCommand(commands.CreateImportCommand())
 	c.AddCommand(commands.CreateInferCmd())
 	c.AddCommand(commands.CreatePortfolioCommand())
+	c.AddCommand(commands.CreatePricesCommand())
 	c.AddCommand(commands.CreateFetchCommand())
 	c.AddCommand(commands.CreateRegisterCmd())
+	c.AddCommand(commands.CreateSchemaCommand())
 	c.AddCommand(commands.CreateTranscodeCommand())
+	c.AddCommand(commands.CreateTailCommand())
 	c.AddCommand(commands.CreatePrintCommand())
 
 	return c
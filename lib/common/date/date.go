@@ -102,6 +102,16 @@ func StartOf(d time.Time, p Interval) time.Time {
 	return d
 }
 
+// FiscalStartOf is like StartOf, with quarters and years aligned to a
+// fiscal year beginning in the given month.
+func FiscalStartOf(d time.Time, p Interval, start time.Month) time.Time {
+	if p != Quarterly && p != Yearly {
+		return StartOf(d, p)
+	}
+	shift := int(start - time.January)
+	return StartOf(Date(d.Year(), d.Month()-time.Month(shift), 1), p).AddDate(0, shift, 0)
+}
+
 // EndOf returns the last date in the given period that contains
 // the receiver.
 func EndOf(d time.Time, p Interval) time.Time {
@@ -124,12 +134,57 @@ func EndOf(d time.Time, p Interval) time.Time {
 	return d
 }
 
+// Label formats the given date for use as a column header. The style
+// is either one of the named styles "month", "quarter" and "year" or a
+// Go time layout. An empty style yields the ISO date.
+func Label(d time.Time, style string) string {
+	switch style {
+	case "":
+		return d.Format("2006-01-02")
+	case "month":
+		return d.Format("Jan 2006")
+	case "quarter":
+		return fmt.Sprintf("Q%d %d", (d.Month()-1)/3+1, d.Year())
+	case "year":
+		return d.Format("2006")
+	}
+	return d.Format(style)
+}
+
 // Today returns today's
 func Today() time.Time {
 	now := time.Now().Local()
 	return Date(now.Year(), now.Month(), now.Day())
 }
 
+// Ranges are the names of the period presets understood by Range.
+var Ranges = []string{"ytd", "qtd", "mtd", "last-year", "last-quarter", "last-month", "last-90d"}
+
+// Range returns the period described by the given preset, relative to
+// today.
+func Range(name string, today time.Time) (Period, error) {
+	switch name {
+	case "ytd":
+		return Period{Start: StartOf(today, Yearly), End: today}, nil
+	case "qtd":
+		return Period{Start: StartOf(today, Quarterly), End: today}, nil
+	case "mtd":
+		return Period{Start: StartOf(today, Monthly), End: today}, nil
+	case "last-year":
+		d := StartOf(today, Yearly).AddDate(0, 0, -1)
+		return Period{Start: StartOf(d, Yearly), End: d}, nil
+	case "last-quarter":
+		d := StartOf(today, Quarterly).AddDate(0, 0, -1)
+		return Period{Start: StartOf(d, Quarterly), End: d}, nil
+	case "last-month":
+		d := StartOf(today, Monthly).AddDate(0, 0, -1)
+		return Period{Start: StartOf(d, Monthly), End: d}, nil
+	case "last-90d":
+		return Period{Start: today.AddDate(0, 0, -89), End: today}, nil
+	}
+	return Period{}, fmt.Errorf("invalid range: %s", name)
+}
+
 type Period struct {
 	Start, End time.Time
 }
@@ -152,13 +207,38 @@ type Partition struct {
 	span     Period
 	interval Interval
 	periods  []Period
+	carry    bool
 }
 
 func (part Partition) Contains(d time.Time) bool {
 	return part.span.Contains(d)
 }
 
+// Retains returns whether entries at the given date contribute to the
+// partition. A snapshot also retains the entries before its start, which
+// carries the opening balances forward into its single period.
+func (part Partition) Retains(d time.Time) bool {
+	if part.carry && d.Before(part.span.Start) {
+		return true
+	}
+	return part.span.Contains(d)
+}
+
+// NewSnapshot creates a partition with a single period covering the
+// given period, which includes the opening balances at its start.
+func NewSnapshot(period Period) Partition {
+	part := NewPartition(period, Once, 0)
+	part.carry = true
+	return part
+}
+
 func NewPartition(period Period, interval Interval, last int) Partition {
+	return NewFiscalPartition(period, interval, last, time.January)
+}
+
+// NewFiscalPartition creates a partition whose quarters and years are
+// aligned to a fiscal year beginning in the given month.
+func NewFiscalPartition(period Period, interval Interval, last int, fiscalStart time.Month) Partition {
 	if period.Start.IsZero() {
 		panic("can't create partition with zero time")
 	}
@@ -169,7 +249,7 @@ func NewPartition(period Period, interval Interval, last int) Partition {
 		var start time.Time
 		var counter int
 		for end := period.End; !end.Before(period.Start) && !(counter >= last && last > 0); end = start.AddDate(0, 0, -1) {
-			start = StartOf(end, interval)
+			start = FiscalStartOf(end, interval, fiscalStart)
 			if start.Before(period.Start) {
 				start = period.Start
 			}
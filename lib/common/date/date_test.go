@@ -74,6 +74,104 @@ func TestStartOf(t *testing.T) {
 	}
 }
 
+func TestFiscalStartOf(t *testing.T) {
+	tests := []struct {
+		date   time.Time
+		start  time.Month
+		result map[Interval]time.Time
+	}{
+		{
+			date:  Date(2020, 2, 15),
+			start: time.April,
+			result: map[Interval]time.Time{
+				Monthly:   Date(2020, 2, 1),
+				Quarterly: Date(2020, 1, 1),
+				Yearly:    Date(2019, 4, 1),
+			},
+		},
+		{
+			date:  Date(2020, 5, 31),
+			start: time.April,
+			result: map[Interval]time.Time{
+				Quarterly: Date(2020, 4, 1),
+				Yearly:    Date(2020, 4, 1),
+			},
+		},
+		{
+			date:  Date(2020, 12, 31),
+			start: time.July,
+			result: map[Interval]time.Time{
+				Quarterly: Date(2020, 10, 1),
+				Yearly:    Date(2020, 7, 1),
+			},
+		},
+		{
+			date:  Date(2020, 3, 31),
+			start: time.January,
+			result: map[Interval]time.Time{
+				Quarterly: Date(2020, 1, 1),
+				Yearly:    Date(2020, 1, 1),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for interval, result := range test.result {
+			if got := FiscalStartOf(test.date, interval, test.start); got != result {
+				t.Errorf("FiscalStartOf(%v, %v, %v): Got %v, wanted %v", test.date, interval, test.start, got, result)
+			}
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"", "2020-05-31"},
+		{"month", "May 2020"},
+		{"quarter", "Q2 2020"},
+		{"year", "2020"},
+		{"02.01.06", "31.05.20"},
+	}
+
+	for _, test := range tests {
+		if got := Label(Date(2020, 5, 31), test.style); got != test.want {
+			t.Errorf("Label(%q): Got %q, wanted %q", test.style, got, test.want)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	today := Date(2024, 5, 15)
+	tests := []struct {
+		name string
+		want Period
+	}{
+		{"ytd", Period{Date(2024, 1, 1), today}},
+		{"qtd", Period{Date(2024, 4, 1), today}},
+		{"mtd", Period{Date(2024, 5, 1), today}},
+		{"last-year", Period{Date(2023, 1, 1), Date(2023, 12, 31)}},
+		{"last-quarter", Period{Date(2024, 1, 1), Date(2024, 3, 31)}},
+		{"last-month", Period{Date(2024, 4, 1), Date(2024, 4, 30)}},
+		{"last-90d", Period{Date(2024, 2, 16), today}},
+	}
+
+	for _, test := range tests {
+		got, err := Range(test.name, today)
+		if err != nil {
+			t.Fatalf("Range(%q): unexpected error %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("Range(%q): Got %v, wanted %v", test.name, got, test.want)
+		}
+	}
+	if _, err := Range("foo", today); err == nil {
+		t.Errorf("Range(%q): expected an error", "foo")
+	}
+}
+
 func TestEndOf(t *testing.T) {
 	tests := []struct {
 		date   time.Time
@@ -194,3 +292,39 @@ func TestPartitionEndDates(t *testing.T) {
 		})
 	}
 }
+
+func TestFiscalPartitionEndDates(t *testing.T) {
+	part := NewFiscalPartition(Period{Start: Date(2019, 1, 1), End: Date(2020, 6, 30)}, Yearly, 0, time.April)
+
+	got := part.EndDates()
+
+	want := []time.Time{Date(2019, 3, 31), Date(2020, 3, 31), Date(2020, 6, 30)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("EndDates(): unexpected diff (+got/-want):\n%s", diff)
+	}
+}
+
+func TestSnapshotRetains(t *testing.T) {
+	period := Period{Start: Date(2021, 1, 1), End: Date(2021, 6, 30)}
+	tests := []struct {
+		date                time.Time
+		partition, snapshot bool
+	}{
+		{date: Date(2020, 12, 31), partition: false, snapshot: true},
+		{date: Date(2021, 1, 1), partition: true, snapshot: true},
+		{date: Date(2021, 6, 30), partition: true, snapshot: true},
+		{date: Date(2021, 7, 1), partition: false, snapshot: false},
+	}
+	part, snap := NewPartition(period, Once, 0), NewSnapshot(period)
+	if diff := cmp.Diff(part.EndDates(), snap.EndDates()); diff != "" {
+		t.Fatalf("NewSnapshot(%v): unexpected diff (+got/-want):\n%s", period, diff)
+	}
+	for _, test := range tests {
+		if got := part.Retains(test.date); got != test.partition {
+			t.Errorf("NewPartition(%v).Retains(%v): got %t, want %t", period, test.date, got, test.partition)
+		}
+		if got := snap.Retains(test.date); got != test.snapshot {
+			t.Errorf("NewSnapshot(%v).Retains(%v): got %t, want %t", period, test.date, got, test.snapshot)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/shopspring/decimal"
+	"golang.org/x/exp/slices"
 )
 
 // TextRenderer renders a table to text.
@@ -31,6 +32,98 @@ type TextRenderer struct {
 	Color     bool
 	Thousands bool
 	Round     int32
+	Rounding  Rounding
+	Border    bool
+	Sparkline bool
+
+	frame frame
+}
+
+// withSparklines returns a copy of the table with an additional column
+// holding a sparkline of the numbers in each row.
+func withSparklines(t *Table) *Table {
+	res := &Table{columns: append(slices.Clone(t.columns), t.columns[len(t.columns)-1]+1)}
+	for _, row := range t.rows {
+		r := res.AddRow()
+		r.cells = append(r.cells, row.cells...)
+		var ns []decimal.Decimal
+		for _, c := range row.cells {
+			if n, ok := c.(numberCell); ok {
+				ns = append(ns, n.n)
+			}
+		}
+		switch {
+		case len(row.cells) > 0 && row.cells[0].isSep():
+			r.addCell(SeparatorCell{})
+		case len(ns) > 0:
+			r.AddText(sparkline(ns), Left)
+		default:
+			r.AddEmpty()
+		}
+	}
+	return res
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the numbers as a sparkline, scaled to the largest
+// absolute value. If there are negative numbers, zero is drawn as a
+// midline, with positive numbers above and negative numbers below.
+func sparkline(ns []decimal.Decimal) string {
+	var peak decimal.Decimal
+	var negative bool
+	for _, n := range ns {
+		if n.Abs().GreaterThan(peak) {
+			peak = n.Abs()
+		}
+		negative = negative || n.IsNegative()
+	}
+	var b strings.Builder
+	for _, n := range ns {
+		switch {
+		case peak.IsZero():
+			b.WriteRune(sparks[0])
+		case !negative:
+			b.WriteRune(sparks[level(n, peak, len(sparks)-1)])
+		case n.IsZero():
+			b.WriteRune('─')
+		case n.IsPositive():
+			b.WriteRune(sparks[len(sparks)/2+level(n, peak, len(sparks)/2-1)])
+		default:
+			b.WriteRune(sparks[len(sparks)/2-1-level(n.Neg(), peak, len(sparks)/2-1)])
+		}
+	}
+	return b.String()
+}
+
+// level scales the non-negative n relative to peak to an integer in [0, max].
+func level(n, peak decimal.Decimal, max int) int {
+	return int(n.Mul(decimal.NewFromInt(int64(max))).Div(peak).Round(0).IntPart())
+}
+
+// Rounding is the rounding mode used to display numbers.
+type Rounding int
+
+const (
+	// HalfUp rounds half away from zero.
+	HalfUp Rounding = iota
+	// HalfEven rounds half to the nearest even digit (banker's rounding).
+	HalfEven
+	// Down rounds towards zero.
+	Down
+)
+
+// ParseRounding parses a rounding mode.
+func ParseRounding(s string) (Rounding, error) {
+	switch s {
+	case "half-up":
+		return HalfUp, nil
+	case "half-even":
+		return HalfEven, nil
+	case "down":
+		return Down, nil
+	}
+	return HalfUp, fmt.Errorf("invalid rounding mode %q, want half-up, half-even or down", s)
 }
 
 var (
@@ -40,8 +133,16 @@ var (
 
 // Render renders this table to a string.
 func (r *TextRenderer) Render(t *Table, w io.Writer) error {
+	if r.Sparkline {
+		t = withSparklines(t)
+	}
 	r.table = t
 	color.NoColor = !r.Color
+	if r.Border {
+		r.frame = boxFrame
+	} else {
+		r.frame = asciiFrame
+	}
 
 	widths := make([]int, r.table.Width())
 	for _, row := range r.table.rows {
@@ -62,31 +163,32 @@ func (r *TextRenderer) Render(t *Table, w io.Writer) error {
 			widths[i] = groups[i]
 		}
 	}
-	for _, row := range r.table.rows {
+	for i, row := range r.table.rows {
+		pos := r.position(i)
 		if row.cells[0].isSep() {
-			if _, err := io.WriteString(w, "+-"); err != nil {
+			if _, err := io.WriteString(w, r.frame.left[pos]+r.frame.horizontal); err != nil {
 				return err
 			}
 		} else {
-			if _, err := io.WriteString(w, "| "); err != nil {
+			if _, err := io.WriteString(w, r.frame.vertical+" "); err != nil {
 				return err
 			}
 		}
 
-		for i, c := range row.cells {
-			r.renderCell(c, widths[i], w)
-			if i < len(row.cells)-1 {
-				if _, err := io.WriteString(w, createSep(c, row.cells[i+1])); err != nil {
+		for j, c := range row.cells {
+			r.renderCell(c, widths[j], w)
+			if j < len(row.cells)-1 {
+				if _, err := io.WriteString(w, r.createSep(pos, c, row.cells[j+1])); err != nil {
 					return err
 				}
 			}
 		}
 		if row.cells[len(row.cells)-1].isSep() {
-			if _, err := io.WriteString(w, "-+\n"); err != nil {
+			if _, err := io.WriteString(w, r.frame.horizontal+r.frame.right[pos]+"\n"); err != nil {
 				return err
 			}
 		} else {
-			if _, err := io.WriteString(w, " |\n"); err != nil {
+			if _, err := io.WriteString(w, " "+r.frame.vertical+"\n"); err != nil {
 				return err
 			}
 		}
@@ -103,7 +205,7 @@ func (r *TextRenderer) renderCell(c cell, l int, w io.Writer) error {
 		return writeSpace(w, l)
 
 	case SeparatorCell:
-		return writeStrings(w, "-", l)
+		return writeStrings(w, r.frame.horizontal, l)
 
 	case textCell:
 		var before int
@@ -129,6 +231,8 @@ func (r *TextRenderer) renderCell(c cell, l int, w io.Writer) error {
 		switch {
 		case t.n.LessThan(decimal.Zero):
 			_, err = red.Fprintf(w, "%*s", l, s)
+		case t.n.Equal(decimal.Zero) && t.explicit:
+			_, err = fmt.Fprintf(w, "%*s", l, s)
 		case t.n.Equal(decimal.Zero):
 			_, err = fmt.Fprintf(w, "%*s", l, "")
 		case t.n.GreaterThan(decimal.Zero):
@@ -186,25 +290,74 @@ func (r *TextRenderer) minLengthCell(c cell) int {
 	return 0
 }
 
-func createSep(c1, c2 cell) string {
+func (r *TextRenderer) createSep(pos int, c1, c2 cell) string {
+	f := r.frame
 	switch {
 	case c1.isSep() && c2.isSep():
-		return "-+-"
+		return f.horizontal + f.cross[pos] + f.horizontal
 	case c1.isSep():
-		return "-+ "
+		return f.horizontal + f.right[pos] + " "
 	case c2.isSep():
-		return " +-"
+		return " " + f.left[pos] + f.horizontal
 	default:
-		return " | "
+		return " " + f.vertical + " "
+	}
+}
+
+// position returns whether the row with the given index is at the top,
+// in the middle or at the bottom of the table.
+func (r *TextRenderer) position(i int) int {
+	switch i {
+	case 0:
+		return top
+	case len(r.table.rows) - 1:
+		return bottom
 	}
+	return middle
 }
 
+const (
+	top = iota
+	middle
+	bottom
+)
+
+// frame defines the characters used to draw the table borders. Junctions
+// are indexed by the vertical position of the row (top, middle, bottom).
+type frame struct {
+	vertical, horizontal string
+	left, cross, right   [3]string
+}
+
+var (
+	asciiFrame = frame{
+		vertical:   "|",
+		horizontal: "-",
+		left:       [3]string{"+", "+", "+"},
+		cross:      [3]string{"+", "+", "+"},
+		right:      [3]string{"+", "+", "+"},
+	}
+	boxFrame = frame{
+		vertical:   "│",
+		horizontal: "─",
+		left:       [3]string{"┌", "├", "└"},
+		cross:      [3]string{"┬", "┼", "┴"},
+		right:      [3]string{"┐", "┤", "┘"},
+	}
+)
+
 var k = decimal.RequireFromString("1000")
 
 func (r *TextRenderer) numToString(d decimal.Decimal) string {
 	if r.Thousands {
 		d = d.Div(k)
 	}
+	switch r.Rounding {
+	case HalfEven:
+		d = d.RoundBank(r.Round)
+	case Down:
+		d = d.Truncate(r.Round)
+	}
 	return addThousandsSep(d.StringFixed(r.Round))
 }
 
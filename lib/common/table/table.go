@@ -43,6 +43,21 @@ func (t *Table) Width() int {
 	return len(t.columns)
 }
 
+// SelectColumns returns a new table consisting of the given columns.
+func (t *Table) SelectColumns(cols ...int) *Table {
+	res := &Table{}
+	for _, c := range cols {
+		res.columns = append(res.columns, t.columns[c])
+	}
+	for _, row := range t.rows {
+		r := res.AddRow()
+		for _, c := range cols {
+			r.addCell(row.cells[c])
+		}
+	}
+	return res
+}
+
 // AddRow adds a row.
 func (t *Table) AddRow() *Row {
 	var (
@@ -96,7 +111,13 @@ func (r *Row) AddText(content string, align Alignment) *Row {
 
 // AddDecimal adds a number cell.
 func (r *Row) AddDecimal(n decimal.Decimal) *Row {
-	r.addCell(numberCell{n})
+	r.addCell(numberCell{n: n})
+	return r
+}
+
+// AddZero adds a number cell with a zero which is rendered explicitly.
+func (r *Row) AddZero() *Row {
+	r.addCell(numberCell{n: decimal.Zero, explicit: true})
 	return r
 }
 
@@ -152,6 +173,9 @@ func (t textCell) isSep() bool {
 // textCell is a cell containing text.
 type numberCell struct {
 	n decimal.Decimal
+
+	// explicit renders a zero instead of leaving the cell blank.
+	explicit bool
 }
 
 func (t numberCell) isSep() bool {
@@ -14,7 +14,13 @@
 
 package table
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
 
 func TestAddThousandsSep(t *testing.T) {
 	tests := []struct {
@@ -46,3 +52,112 @@ func TestAddThousandsSep(t *testing.T) {
 		})
 	}
 }
+
+func TestNumToString(t *testing.T) {
+	tests := []struct {
+		input    string
+		rounding Rounding
+		want     string
+	}{
+		{"2.5", HalfUp, "3"},
+		{"2.5", HalfEven, "2"},
+		{"3.5", HalfEven, "4"},
+		{"-2.5", HalfEven, "-2"},
+		{"2.9", Down, "2"},
+		{"-2.9", Down, "-2"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("%s/%d", test.input, test.rounding), func(t *testing.T) {
+			r := TextRenderer{Rounding: test.rounding}
+
+			got := r.numToString(decimal.RequireFromString(test.input))
+
+			if got != test.want {
+				t.Errorf("numToString(%q) = %q, want %q", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTextRendererBorder(t *testing.T) {
+	tbl := New(1, 1)
+	tbl.AddSeparatorRow()
+	tbl.AddRow().AddText("Account", Center).AddText("Amount", Center)
+	tbl.AddSeparatorRow()
+	tbl.AddRow().AddText("Assets", Left).AddDecimal(decimal.RequireFromString("1234.5"))
+	tbl.AddSeparatorRow()
+
+	tests := []struct {
+		border bool
+		want   string
+	}{
+		{
+			border: false,
+			want: strings.Join([]string{
+				"+---------+--------+",
+				"| Account | Amount |",
+				"+---------+--------+",
+				"| Assets  |  1,235 |",
+				"+---------+--------+",
+				"",
+				"",
+			}, "\n"),
+		},
+		{
+			border: true,
+			want: strings.Join([]string{
+				"┌─────────┬────────┐",
+				"│ Account │ Amount │",
+				"├─────────┼────────┤",
+				"│ Assets  │  1,235 │",
+				"└─────────┴────────┘",
+				"",
+				"",
+			}, "\n"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("border=%t", test.border), func(t *testing.T) {
+			var b strings.Builder
+			r := TextRenderer{Border: test.border}
+
+			if err := r.Render(tbl, &b); err != nil {
+				t.Fatalf("r.Render() returned unexpected error: %v", err)
+			}
+
+			if got := b.String(); got != test.want {
+				t.Errorf("r.Render() = \n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  string
+	}{
+		{[]string{"0", "1", "2", "4"}, "▁▃▅█"},
+		{[]string{"0", "0"}, "▁▁"},
+		{[]string{"-3", "0", "3"}, "▁─█"},
+		{[]string{"-1", "1", "3"}, "▃▆█"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(strings.Join(test.input, ","), func(t *testing.T) {
+			var ns []decimal.Decimal
+			for _, s := range test.input {
+				ns = append(ns, decimal.RequireFromString(s))
+			}
+
+			if got := sparkline(ns); got != test.want {
+				t.Errorf("sparkline(%v) = %q, want %q", test.input, got, test.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/sboehler/knut/lib/amounts"
 	"github.com/sboehler/knut/lib/common/set"
 	"github.com/sboehler/knut/lib/journal"
 	"github.com/sboehler/knut/lib/journal/printer"
 	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/account"
 	"github.com/sboehler/knut/lib/model/assertion"
+	"github.com/sboehler/knut/lib/model/commodity"
 	"golang.org/x/exp/slices"
 )
 
@@ -30,10 +34,22 @@ func (be Error) Error() string {
 	return s.String()
 }
 
+// Checker checks that accounts are open when they are used and have no
+// positions when they are closed, and verifies balance assertions. This
+// applies to equity accounts as well: they are never implicitly open.
+// Positions are adjusted for splits.
 type Checker struct {
 	Write   bool
 	NoCheck bool
 
+	// Warn, if not nil, receives transactions which multiply a position
+	// without a split, which usually means that a split is missing.
+	Warn func(error)
+
+	// Fail, if not nil, receives failed balance assertions, which then
+	// do not abort the check.
+	Fail func(error)
+
 	quantities amounts.Amounts
 	accounts   set.Set[*model.Account]
 	assertions []*model.Assertion
@@ -56,7 +72,45 @@ func (ch *Checker) posting(t *model.Transaction, p *model.Posting) error {
 		return Error{Directive: t, Msg: fmt.Sprintf("account %s is not open", p.Account)}
 	}
 	if p.Account.IsAL() {
-		ch.quantities.Add(amounts.AccountCommodityKey(p.Account, p.Commodity), p.Quantity)
+		key := amounts.AccountCommodityKey(p.Account, p.Commodity)
+		if ch.Warn != nil && isJump(t, p, ch.quantities[key]) {
+			ch.Warn(Error{Directive: t, Msg: fmt.Sprintf("suspicious change of position %s %s in account %s, is a split missing?", ch.quantities[key], p.Commodity.Name(), p.Account.Name())})
+		}
+		ch.quantities.Add(key, p.Quantity)
+	}
+	return nil
+}
+
+// isJump returns whether the posting multiplies or divides the given position
+// by an integer factor, without any other commodity being involved and with
+// an equity counter account. Splits recorded by hand book shares against
+// equity, while income and expenses are regular cash flows, which
+// multiply a position by chance.
+func isJump(t *model.Transaction, p *model.Posting, qty decimal.Decimal) bool {
+	if qty.IsZero() || p.Other.Type() != account.EQUITY {
+		return false
+	}
+	for _, o := range t.Postings {
+		if o.Commodity != p.Commodity {
+			return false
+		}
+	}
+	before, after := qty.Abs(), qty.Add(p.Quantity).Abs()
+	if after.IsZero() || qty.IsPositive() != qty.Add(p.Quantity).IsPositive() {
+		return false
+	}
+	if after.LessThan(before) {
+		before, after = after, before
+	}
+	factor := after.Div(before)
+	return factor.IsInteger() && factor.GreaterThan(decimal.NewFromInt(1))
+}
+
+func (ch *Checker) split(s *model.Split) error {
+	for pos, qty := range ch.quantities {
+		if pos.Commodity == s.Commodity {
+			ch.quantities[pos] = s.Quantity(qty)
+		}
 	}
 	return nil
 }
@@ -70,17 +124,27 @@ func (ch *Checker) balance(a *model.Assertion, bal *model.Balance) error {
 		return nil
 	}
 	if qty, ok := ch.quantities[position]; !ok || !qty.Equal(bal.Quantity) {
-		return Error{Directive: a, Msg: fmt.Sprintf("failed assertion: %s has position: %s %s", position.Account.Name(), qty, position.Commodity.Name())}
+		err := Error{Directive: a, Msg: fmt.Sprintf("failed assertion: %s has position: %s %s", position.Account.Name(), qty, position.Commodity.Name())}
+		if ch.Fail == nil {
+			return err
+		}
+		ch.Fail(err)
 	}
 	return nil
 }
 
 func (ch *Checker) close(c *model.Close) error {
-	for pos, amount := range ch.quantities {
-		if pos.Account != c.Account {
-			continue
+	var positions []amounts.Key
+	for pos := range ch.quantities {
+		if pos.Account == c.Account {
+			positions = append(positions, pos)
 		}
-		if !amount.IsZero() {
+	}
+	slices.SortFunc(positions, func(p1, p2 amounts.Key) int {
+		return int(commodity.Compare(p1.Commodity, p2.Commodity))
+	})
+	for _, pos := range positions {
+		if amount := ch.quantities[pos]; !amount.IsZero() {
 			return Error{Directive: c, Msg: fmt.Sprintf("account has nonzero position: %s %s", amount, pos.Commodity.Name())}
 		}
 		delete(ch.quantities, pos)
@@ -124,6 +188,7 @@ func (ch *Checker) Check() *journal.Processor {
 
 	return &journal.Processor{
 		Open:    ch.open,
+		Split:   ch.split,
 		Posting: ch.posting,
 		Balance: ch.balance,
 		Close:   ch.close,
@@ -0,0 +1,62 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/posting"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/model/transaction"
+)
+
+func TestCheckWarnsOnJumps(t *testing.T) {
+	tests := []struct {
+		desc  string
+		other string
+		want  int
+	}{
+		{desc: "salary doubling cash", other: "Income:Salary", want: 0},
+		{desc: "refund doubling cash", other: "Expenses:Groceries", want: 0},
+		{desc: "shares doubled against equity", other: "Equity:Split", want: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var (
+				reg   = registry.New()
+				com   = reg.Commodities().MustGet("CHF")
+				bank  = reg.Accounts().MustGet("Assets:Bank")
+				other = reg.Accounts().MustGet(test.other)
+				b     = journal.New()
+			)
+			for _, a := range []*model.Account{bank, other} {
+				b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: a})
+			}
+			for d := 2; d <= 3; d++ {
+				b.Add(transaction.Builder{
+					Date:        date.Date(2020, 1, d),
+					Description: "booking",
+					Postings: posting.Builder{
+						Credit:    other,
+						Debit:     bank,
+						Commodity: com,
+						Quantity:  decimal.NewFromInt(1000),
+					}.Build(),
+				}.Build())
+			}
+			var warnings int
+			checker := Checker{Warn: func(error) { warnings++ }}
+
+			if err := b.Build().Process(checker.Check()); err != nil {
+				t.Fatalf("Process(): unexpected error %v", err)
+			}
+
+			if warnings != test.want {
+				t.Fatalf("got %d warnings, want %d", warnings, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package check
+
+import (
+	"time"
+
+	"github.com/sboehler/knut/lib/amounts"
+	"github.com/sboehler/knut/lib/common/set"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+)
+
+// Closer suggests close directives for open accounts which have no
+// activity after a given date and no remaining positions.
+type Closer struct {
+	// Since is the date after which an account must not have any activity.
+	Since time.Time
+
+	quantities   amounts.Amounts
+	accounts     []*model.Account
+	open         set.Set[*model.Account]
+	lastActivity map[*model.Account]time.Time
+}
+
+// Suggest returns a processor collecting the activity of all accounts.
+func (cl *Closer) Suggest() *journal.Processor {
+	cl.quantities = make(amounts.Amounts)
+	cl.accounts = nil
+	cl.open = set.New[*model.Account]()
+	cl.lastActivity = make(map[*model.Account]time.Time)
+
+	return &journal.Processor{
+		Open: func(o *model.Open) error {
+			if _, ok := cl.lastActivity[o.Account]; !ok {
+				cl.accounts = append(cl.accounts, o.Account)
+			}
+			cl.open.Add(o.Account)
+			cl.lastActivity[o.Account] = o.Date
+			return nil
+		},
+		Split: func(s *model.Split) error {
+			for pos, qty := range cl.quantities {
+				if pos.Commodity == s.Commodity {
+					cl.quantities[pos] = s.Quantity(qty)
+				}
+			}
+			return nil
+		},
+		Posting: func(t *model.Transaction, p *model.Posting) error {
+			if p.Account.IsAL() {
+				cl.quantities.Add(amounts.AccountCommodityKey(p.Account, p.Commodity), p.Quantity)
+			}
+			cl.lastActivity[p.Account] = t.Date
+			return nil
+		},
+		Balance: func(a *model.Assertion, bal *model.Balance) error {
+			cl.lastActivity[bal.Account] = a.Date
+			return nil
+		},
+		Close: func(c *model.Close) error {
+			cl.open.Remove(c.Account)
+			return nil
+		},
+	}
+}
+
+// Closes returns a close directive for each open account without a
+// position and without activity after Since, dated at its last activity.
+func (cl *Closer) Closes() []*model.Close {
+	held := set.New[*model.Account]()
+	for pos, qty := range cl.quantities {
+		if !qty.IsZero() {
+			held.Add(pos.Account)
+		}
+	}
+	var res []*model.Close
+	for _, a := range cl.accounts {
+		if !cl.open.Has(a) || held.Has(a) || cl.lastActivity[a].After(cl.Since) {
+			continue
+		}
+		res = append(res, &model.Close{Date: cl.lastActivity[a], Account: a})
+	}
+	return res
+}
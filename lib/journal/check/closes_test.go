@@ -0,0 +1,62 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/posting"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/model/transaction"
+)
+
+func TestCloserCloses(t *testing.T) {
+	var (
+		reg     = registry.New()
+		chf     = reg.Commodities().MustGet("CHF")
+		old     = reg.Accounts().MustGet("Assets:Old")
+		bank    = reg.Accounts().MustGet("Assets:Bank")
+		gym     = reg.Accounts().MustGet("Expenses:Gym")
+		closed  = reg.Accounts().MustGet("Expenses:Closed")
+		opening = reg.Accounts().MustGet("Equity:Opening")
+	)
+	b := journal.New()
+	for _, a := range []*model.Account{old, bank, gym, closed, opening} {
+		b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: a})
+	}
+	book := func(d int, credit, debit *model.Account) {
+		b.Add(transaction.Builder{
+			Date:        date.Date(2020, 1, d),
+			Description: "booking",
+			Postings: posting.Builder{
+				Credit:    credit,
+				Debit:     debit,
+				Commodity: chf,
+				Quantity:  decimal.NewFromInt(10),
+			}.Build(),
+		}.Build())
+	}
+	book(2, opening, old)
+	book(3, old, bank)
+	book(4, bank, gym)
+	book(5, bank, closed)
+	b.Add(&model.Close{Date: date.Date(2020, 1, 6), Account: closed})
+	b.Add(&model.Assertion{Date: date.Date(2020, 1, 20), Balances: []model.Balance{{Account: old, Commodity: chf}}})
+	closer := Closer{Since: date.Date(2020, 1, 10)}
+
+	if err := b.Build().Process(closer.Suggest()); err != nil {
+		t.Fatalf("Process(): unexpected error %v", err)
+	}
+
+	want := []*model.Close{
+		{Date: date.Date(2020, 1, 4), Account: gym},
+		{Date: date.Date(2020, 1, 2), Account: opening},
+	}
+	if diff := cmp.Diff(want, closer.Closes(), cmp.AllowUnexported(model.Account{})); diff != "" {
+		t.Fatalf("Closes(): unexpected diff (+got/-want):\n%s", diff)
+	}
+}
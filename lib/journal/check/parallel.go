@@ -0,0 +1,180 @@
+package check
+
+import (
+	"runtime"
+
+	"github.com/sourcegraph/conc/pool"
+	"golang.org/x/exp/slices"
+
+	"github.com/sboehler/knut/lib/amounts"
+	"github.com/sboehler/knut/lib/common/set"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+)
+
+// The phases of a day, in the order in which journal.Processor visits them.
+const (
+	phaseSplit = iota
+	phaseOpen
+	phasePosting
+	phaseBalance
+	phaseClose
+)
+
+// position locates a check in the serial processing order.
+type position struct {
+	day, phase, item, sub int
+}
+
+func (p position) less(q position) bool {
+	if p.day != q.day {
+		return p.day < q.day
+	}
+	if p.phase != q.phase {
+		return p.phase < q.phase
+	}
+	if p.item != q.item {
+		return p.item < q.item
+	}
+	return p.sub < q.sub
+}
+
+type event struct {
+	pos position
+	run func(*Checker) error
+}
+
+type report struct {
+	pos position
+	err error
+}
+
+type shardResult struct {
+	err             *report
+	warns, failures []report
+}
+
+// Parallel checks the journal like Check, with the checks of each account
+// running concurrently. Accounts are independent, so the result equals
+// the serial result: the first error in journal order is returned, and the
+// warnings and failures preceding it are passed to Warn and Fail in journal
+// order. Writing assertions needs the positions of all accounts and falls
+// back to a serial check.
+func (ch *Checker) Parallel(j *journal.Journal) error {
+	if ch.Write {
+		return j.Process(ch.Check())
+	}
+	var accounts []*model.Account
+	shards := make(map[*model.Account][]event)
+	add := func(a *model.Account, e event) {
+		if _, ok := shards[a]; !ok {
+			accounts = append(accounts, a)
+		}
+		shards[a] = append(shards[a], e)
+	}
+	for i, d := range j.Days {
+		for k, s := range d.Splits {
+			s := s
+			for _, a := range accounts {
+				add(a, event{position{i, phaseSplit, k, 0}, func(ch *Checker) error { return ch.split(s) }})
+			}
+		}
+		for k, o := range d.Openings {
+			o := o
+			add(o.Account, event{position{i, phaseOpen, k, 0}, func(ch *Checker) error { return ch.open(o) }})
+		}
+		for k, t := range d.Transactions {
+			t := t
+			for l, p := range t.Postings {
+				p := p
+				add(p.Account, event{position{i, phasePosting, k, l}, func(ch *Checker) error { return ch.posting(t, p) }})
+			}
+		}
+		for k, a := range d.Assertions {
+			a := a
+			for l := range a.Balances {
+				bal := &a.Balances[l]
+				add(bal.Account, event{position{i, phaseBalance, k, l}, func(ch *Checker) error { return ch.balance(a, bal) }})
+			}
+		}
+		for k, c := range d.Closings {
+			c := c
+			add(c.Account, event{position{i, phaseClose, k, 0}, func(ch *Checker) error { return ch.close(c) }})
+		}
+	}
+
+	p := pool.NewWithResults[shardResult]().WithMaxGoroutines(runtime.GOMAXPROCS(0))
+	for _, a := range accounts {
+		events := shards[a]
+		p.Go(func() shardResult {
+			return ch.runShard(events)
+		})
+	}
+	var (
+		first           *report
+		warns, failures []report
+	)
+	for _, res := range p.Wait() {
+		if res.err != nil && (first == nil || res.err.pos.less(first.pos)) {
+			first = res.err
+		}
+		warns = append(warns, res.warns...)
+		failures = append(failures, res.failures...)
+	}
+	ch.emit(warns, first, ch.Warn)
+	ch.emit(failures, first, ch.Fail)
+	if first != nil {
+		return first.err
+	}
+	return nil
+}
+
+// runShard runs the events of a single account with a fresh checker, until
+// the first error.
+func (ch *Checker) runShard(events []event) shardResult {
+	var (
+		res shardResult
+		pos position
+	)
+	sub := &Checker{
+		NoCheck:    ch.NoCheck,
+		quantities: make(amounts.Amounts),
+		accounts:   set.New[*model.Account](),
+	}
+	if ch.Warn != nil {
+		sub.Warn = func(err error) { res.warns = append(res.warns, report{pos, err}) }
+	}
+	if ch.Fail != nil {
+		sub.Fail = func(err error) { res.failures = append(res.failures, report{pos, err}) }
+	}
+	for _, e := range events {
+		pos = e.pos
+		if err := e.run(sub); err != nil {
+			res.err = &report{pos, err}
+			break
+		}
+	}
+	return res
+}
+
+// emit passes the reports preceding the first error to f, in journal order.
+func (ch *Checker) emit(rs []report, first *report, f func(error)) {
+	if f == nil {
+		return
+	}
+	slices.SortStableFunc(rs, func(r1, r2 report) int {
+		switch {
+		case r1.pos.less(r2.pos):
+			return -1
+		case r2.pos.less(r1.pos):
+			return 1
+		}
+		return 0
+	})
+	for _, r := range rs {
+		if first != nil && !r.pos.less(first.pos) {
+			break
+		}
+		f(r.err)
+	}
+}
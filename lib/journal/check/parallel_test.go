@@ -0,0 +1,105 @@
+package check
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/posting"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/model/transaction"
+)
+
+func createRandomJournal(seed int64) *journal.Journal {
+	rnd := rand.New(rand.NewSource(seed))
+	reg := registry.New()
+	commodities := []*model.Commodity{reg.Commodities().MustGet("CHF"), reg.Commodities().MustGet("AAPL")}
+	var accounts []*model.Account
+	for i := 0; i < 5; i++ {
+		accounts = append(accounts, reg.Accounts().MustGet(fmt.Sprintf("Assets:Account%d", i)))
+	}
+	accounts = append(accounts, reg.Accounts().MustGet("Expenses:Groceries"))
+	pick := func() *model.Account { return accounts[rnd.Intn(len(accounts))] }
+	b := journal.New()
+	for _, a := range accounts {
+		b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: a})
+	}
+	for i := 1; i < 60; i++ {
+		d := date.Date(2020, 1, 1).AddDate(0, 0, i)
+		switch rnd.Intn(60) {
+		case 0:
+			b.Add(&model.Open{Date: d, Account: pick()})
+		case 1:
+			b.Add(&model.Close{Date: d, Account: pick()})
+		case 2:
+			b.Add(&model.Split{Date: d, Commodity: commodities[1], Numerator: decimal.NewFromInt(2), Denominator: decimal.NewFromInt(1)})
+		case 3, 4, 5, 6, 7, 8:
+			b.Add(&model.Assertion{Date: d, Balances: []model.Balance{{
+				Account:   pick(),
+				Quantity:  decimal.NewFromInt(int64(rnd.Intn(5))),
+				Commodity: commodities[rnd.Intn(len(commodities))],
+			}}})
+		}
+		for k := 0; k < rnd.Intn(4); k++ {
+			b.Add(transaction.Builder{
+				Date:        d,
+				Description: fmt.Sprintf("transaction %d", k),
+				Postings: posting.Builder{
+					Credit:    pick(),
+					Debit:     pick(),
+					Commodity: commodities[rnd.Intn(len(commodities))],
+					Quantity:  decimal.NewFromInt(int64(rnd.Intn(3) + 1)),
+				}.Build(),
+			}.Build())
+		}
+	}
+	return b.Build()
+}
+
+type checkResult struct {
+	Err             string
+	Warns, Failures []string
+}
+
+func runCheck(j *journal.Journal, parallel, fail bool) checkResult {
+	var res checkResult
+	ch := Checker{
+		Warn: func(err error) { res.Warns = append(res.Warns, err.Error()) },
+	}
+	if fail {
+		ch.Fail = func(err error) { res.Failures = append(res.Failures, err.Error()) }
+	}
+	var err error
+	if parallel {
+		err = ch.Parallel(j)
+	} else {
+		err = j.Process(ch.Check())
+	}
+	if err != nil {
+		res.Err = err.Error()
+	}
+	return res
+}
+
+func TestParallel(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		for _, fail := range []bool{false, true} {
+			t.Run(fmt.Sprintf("seed %d fail %t", seed, fail), func(t *testing.T) {
+				j := createRandomJournal(seed)
+
+				want := runCheck(j, false, fail)
+				got := runCheck(j, true, fail)
+
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Fatalf("Parallel(): unexpected diff (+got/-want):\n%s", diff)
+				}
+			})
+		}
+	}
+}
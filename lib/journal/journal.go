@@ -15,9 +15,11 @@
 package journal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"runtime"
 	"strings"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 	"github.com/sboehler/knut/lib/model/price"
 	"github.com/sboehler/knut/lib/syntax"
 	"github.com/sourcegraph/conc/pool"
+	"github.com/sourcegraph/conc/stream"
 )
 
 // Builder represents an unprocessed
@@ -72,6 +75,10 @@ func (j *Builder) Add(d model.Directive) error {
 		d := j.Day(t.Date)
 		d.Openings = append(d.Openings, t)
 
+	case *model.Split:
+		d := j.Day(t.Date)
+		d.Splits = append(d.Splits, t)
+
 	case *model.Transaction:
 		d := j.Day(t.Date)
 		if j.max.Before(d.Date) {
@@ -159,6 +166,7 @@ func (j *Journal) Process(ps ...*Processor) error {
 type Day struct {
 	Date         time.Time
 	Prices       []*model.Price
+	Splits       []*model.Split
 	Assertions   []*model.Assertion
 	Openings     []*model.Open
 	Transactions []*model.Transaction
@@ -204,9 +212,22 @@ func (p Performance) String() string {
 	return buf.String()
 }
 
-// PrintJournal prints a journal.
+// Print prints a journal. Days are formatted in parallel and
+// written in order.
 func Print(w io.Writer, j *Journal) error {
+	return Printer{}.Print(w, j)
+}
+
+// Printer prints journals with options.
+type Printer struct {
+	// MarkTBD adds a comment to postings of the TBD account.
+	MarkTBD bool
+}
+
+// Print prints the journal.
+func (pr Printer) Print(w io.Writer, j *Journal) error {
 	p := printer.New(w)
+	p.MarkTBD = pr.MarkTBD
 	paddingUpdater := &Processor{
 		Transaction: func(t *model.Transaction) error {
 			p.UpdatePadding(t)
@@ -220,51 +241,83 @@ func Print(w io.Writer, j *Journal) error {
 	if err != nil {
 		return err
 	}
+	var errs error
+	s := stream.New().WithMaxGoroutines(runtime.GOMAXPROCS(0))
 	for _, day := range j.Days {
-		for _, pr := range day.Prices {
-			if _, err := p.PrintDirectiveLn(pr); err != nil {
-				return err
+		day := day
+		s.Go(func() stream.Callback {
+			var buf bytes.Buffer
+			err := printDay(p.Fork(&buf), day)
+			return func() {
+				if errs != nil {
+					return
+				}
+				if err != nil {
+					errs = err
+					return
+				}
+				_, errs = buf.WriteTo(p)
 			}
+		})
+	}
+	s.Wait()
+	return errs
+}
+
+func printDay(p *printer.Printer, day *Day) error {
+	for _, pr := range day.Prices {
+		if _, err := p.PrintDirectiveLn(pr); err != nil {
+			return err
 		}
-		if len(day.Prices) > 0 {
-			if _, err := io.WriteString(p, "\n"); err != nil {
-				return err
-			}
+	}
+	if len(day.Prices) > 0 {
+		if _, err := io.WriteString(p, "\n"); err != nil {
+			return err
 		}
-		for _, o := range day.Openings {
-			if _, err := p.PrintDirectiveLn(o); err != nil {
-				return err
-			}
+	}
+	for _, s := range day.Splits {
+		if _, err := p.PrintDirectiveLn(s); err != nil {
+			return err
 		}
-		if len(day.Openings) > 0 {
-			if _, err := io.WriteString(p, "\n"); err != nil {
-				return err
-			}
+	}
+	if len(day.Splits) > 0 {
+		if _, err := io.WriteString(p, "\n"); err != nil {
+			return err
 		}
-		for _, t := range day.Transactions {
-			if _, err := p.PrintDirectiveLn(t); err != nil {
-				return err
-			}
+	}
+	for _, o := range day.Openings {
+		if _, err := p.PrintDirectiveLn(o); err != nil {
+			return err
 		}
-		for _, a := range day.Assertions {
-			if _, err := p.PrintDirectiveLn(a); err != nil {
-				return err
-			}
+	}
+	if len(day.Openings) > 0 {
+		if _, err := io.WriteString(p, "\n"); err != nil {
+			return err
 		}
-		if len(day.Assertions) > 0 {
-			if _, err := io.WriteString(p, "\n"); err != nil {
-				return err
-			}
+	}
+	for _, t := range day.Transactions {
+		if _, err := p.PrintDirectiveLn(t); err != nil {
+			return err
 		}
-		for _, c := range day.Closings {
-			if _, err := p.PrintDirectiveLn(c); err != nil {
-				return err
-			}
+	}
+	for _, a := range day.Assertions {
+		if _, err := p.PrintDirectiveLn(a); err != nil {
+			return err
 		}
-		if len(day.Closings) > 0 {
-			if _, err := io.WriteString(p, "\n"); err != nil {
-				return err
-			}
+	}
+	if len(day.Assertions) > 0 {
+		if _, err := io.WriteString(p, "\n"); err != nil {
+			return err
+		}
+	}
+	for _, c := range day.Closings {
+		if _, err := p.PrintDirectiveLn(c); err != nil {
+			return err
+		}
+	}
+	if len(day.Closings) > 0 {
+		if _, err := io.WriteString(p, "\n"); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -273,6 +326,7 @@ func Print(w io.Writer, j *Journal) error {
 type Processor struct {
 	DayStart    func(*Day) error
 	Price       func(*model.Price) error
+	Split       func(*model.Split) error
 	Open        func(*model.Open) error
 	Transaction func(*model.Transaction) error
 	Posting     func(*model.Transaction, *model.Posting) error
@@ -295,6 +349,13 @@ func (proc *Processor) Process(d *Day) error {
 			}
 		}
 	}
+	if proc.Split != nil {
+		for _, s := range d.Splits {
+			if err := proc.Split(s); err != nil {
+				return err
+			}
+		}
+	}
 	if proc.Open != nil {
 		for _, o := range d.Openings {
 			if err := proc.Open(o); err != nil {
@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/journal/printer"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/posting"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/model/transaction"
+)
+
+func createJournal(days int) *Journal {
+	reg := registry.New()
+	chf := reg.Commodities().MustGet("CHF")
+	b := New()
+	for i := 0; i < days; i++ {
+		d := date.Date(2020, 1, 1).AddDate(0, 0, i)
+		for k := 0; k < 10; k++ {
+			b.Add(transaction.Builder{
+				Date:        d,
+				Description: fmt.Sprintf("transaction %d", k),
+				Postings: posting.Builder{
+					Credit:    reg.Accounts().MustGet(fmt.Sprintf("Assets:Account%d", k)),
+					Debit:     reg.Accounts().MustGet("Expenses:Groceries"),
+					Commodity: chf,
+					Quantity:  decimal.NewFromInt(int64(i*10 + k)),
+				}.Build(),
+			}.Build())
+		}
+		b.Add(&model.Price{Date: d, Commodity: chf, Price: decimal.NewFromInt(int64(i)), Target: reg.Commodities().MustGet("USD")})
+	}
+	return b.Build()
+}
+
+func TestPrint(t *testing.T) {
+	j := createJournal(100)
+	var want bytes.Buffer
+	p := printer.New(&want)
+	for _, d := range j.Days {
+		for _, t := range d.Transactions {
+			p.UpdatePadding(t)
+		}
+	}
+	for _, d := range j.Days {
+		if err := printDay(p, d); err != nil {
+			t.Fatalf("printDay() returned unexpected error: %v", err)
+		}
+	}
+
+	var got bytes.Buffer
+	if err := Print(&got, j); err != nil {
+		t.Fatalf("Print() returned unexpected error: %v", err)
+	}
+
+	if got.String() != want.String() {
+		t.Errorf("Print() = %q, want %q", got.String(), want.String())
+	}
+}
+
+func BenchmarkPrint(b *testing.B) {
+	j := createJournal(3650)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := Print(&buf, j); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPrintSerial(b *testing.B) {
+	j := createJournal(3650)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		p := printer.New(&buf)
+		for _, d := range j.Days {
+			for _, t := range d.Transactions {
+				p.UpdatePadding(t)
+			}
+		}
+		for _, d := range j.Days {
+			if err := printDay(p, d); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package performance
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/sboehler/knut/lib/amounts"
 	"github.com/sboehler/knut/lib/common/date"
@@ -219,6 +220,15 @@ func Performance(dpv *journal.Performance) float64 {
 }
 
 func Perf(j *journal.Builder, part date.Partition) *journal.Processor {
+	return Returns(j, part, func(d time.Time, r float64) error {
+		fmt.Printf("%v: %0.1f%%\n", d, 100*r)
+		return nil
+	})
+}
+
+// Returns calls f with the end date and the return of each period in
+// the partition.
+func Returns(j *journal.Builder, part date.Partition, f func(time.Time, float64) error) *journal.Processor {
 	ds := set.FromSlice(j.Days(part.EndDates()))
 	running := 1.0
 	return &journal.Processor{
@@ -228,7 +238,9 @@ func Perf(j *journal.Builder, part date.Partition) *journal.Processor {
 			}
 			running *= Performance(d.Performance)
 			if ds.Has(d) {
-				fmt.Printf("%v: %0.1f%%\n", d.Date, 100*(running-1))
+				if err := f(d.Date, running-1); err != nil {
+					return err
+				}
 				running = 1.0
 			}
 			return nil
@@ -21,10 +21,14 @@ import (
 	"unicode/utf8"
 
 	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/account"
 )
 
 // Printer prints directives.
 type Printer struct {
+	// MarkTBD adds a comment to postings of the TBD account.
+	MarkTBD bool
+
 	writer  io.Writer
 	padding int
 	count   int
@@ -35,6 +39,11 @@ func New(w io.Writer) *Printer {
 	return &Printer{writer: w}
 }
 
+// Fork returns a printer writing to w, with the same padding as p.
+func (p *Printer) Fork(w io.Writer) *Printer {
+	return &Printer{MarkTBD: p.MarkTBD, writer: w, padding: p.padding}
+}
+
 func (p *Printer) Write(bs []byte) (int, error) {
 	n, err := p.writer.Write(bs)
 	p.count += n
@@ -54,6 +63,8 @@ func (p *Printer) PrintDirective(directive model.Directive) (n int, err error) {
 		return p.printAssertion(d)
 	case *model.Price:
 		return p.printPrice(d)
+	case *model.Split:
+		return p.printSplit(d)
 	}
 	return 0, fmt.Errorf("unknown directive: %v", directive)
 }
@@ -100,7 +111,16 @@ func (p *Printer) printTransaction(t *model.Transaction) (n int, err error) {
 }
 
 func (p *Printer) printPosting(t *model.Posting) (int, error) {
-	return fmt.Fprintf(p, "%-*s %-*s %10s %s", p.padding, t.Other.String(), p.padding, t.Account.String(), t.Quantity.String(), t.Commodity.Name())
+	start := p.count
+	if _, err := fmt.Fprintf(p, "%-*s %-*s %10s %s", p.padding, t.Other.String(), p.padding, t.Account.String(), t.Quantity.String(), t.Commodity.Name()); err != nil {
+		return p.count - start, err
+	}
+	if p.MarkTBD && (t.Account.Name() == account.TBD || t.Other.Name() == account.TBD) {
+		if _, err := io.WriteString(p, " # TODO categorize"); err != nil {
+			return p.count - start, err
+		}
+	}
+	return p.count - start, nil
 }
 
 func (p *Printer) printOpen(o *model.Open) (int, error) {
@@ -115,6 +135,10 @@ func (p *Printer) printPrice(pr *model.Price) (int, error) {
 	return fmt.Fprintf(p, "%s price %s %s %s", pr.Date.Format("2006-01-02"), pr.Commodity.Name(), pr.Price, pr.Target.Name())
 }
 
+func (p *Printer) printSplit(s *model.Split) (int, error) {
+	return fmt.Fprintf(p, "%s split %s %s:%s", s.Date.Format("2006-01-02"), s.Commodity.Name(), s.Numerator, s.Denominator)
+}
+
 func (p *Printer) printAssertion(a *model.Assertion) (int, error) {
 	start := p.count
 	if _, err := fmt.Fprintf(p, "%s balance", a.Date.Format("2006-01-02")); err != nil {
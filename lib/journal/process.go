@@ -2,18 +2,25 @@ package journal
 
 import (
 	"fmt"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/sboehler/knut/lib/amounts"
 	"github.com/sboehler/knut/lib/common/compare"
 	"github.com/sboehler/knut/lib/common/date"
 	"github.com/sboehler/knut/lib/common/mapper"
 	"github.com/sboehler/knut/lib/common/predicate"
+	"github.com/sboehler/knut/lib/common/regex"
 	"github.com/sboehler/knut/lib/common/set"
 	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/account"
+	"github.com/sboehler/knut/lib/model/commodity"
 	"github.com/sboehler/knut/lib/model/posting"
 	"github.com/sboehler/knut/lib/model/price"
 	"github.com/sboehler/knut/lib/model/transaction"
 	"github.com/shopspring/decimal"
+	"golang.org/x/exp/slices"
 )
 
 // ComputePrices updates prices.
@@ -38,15 +45,181 @@ func ComputePrices(v *model.Commodity) *Processor {
 	}
 }
 
+// FreezePrices applies the prices in effect at the start of each period in
+// the partition to all days of that period. Without it, positions are
+// valued at the prices of each day, so a period reflects the prices at its
+// end. It must run between ComputePrices and Valuate.
+func FreezePrices(j *Builder, partition date.Partition, enable bool) *Processor {
+	if !enable {
+		return nil
+	}
+	starts := set.FromSlice(j.Days(partition.StartDates()))
+	var frozen price.NormalizedPrices
+	return &Processor{
+		DayEnd: func(d *Day) error {
+			if !partition.Contains(d.Date) {
+				return nil
+			}
+			if starts.Has(d) {
+				frozen = d.Normalized
+			}
+			if frozen != nil {
+				d.Normalized = frozen
+			}
+			return nil
+		},
+	}
+}
+
+// RecordPrices passes the normalized prices in effect at the end of each
+// period in the partition to the given function. It must run after
+// ComputePrices and FreezePrices.
+func RecordPrices(j *Builder, partition date.Partition, f func(time.Time, price.NormalizedPrices)) *Processor {
+	if f == nil {
+		return nil
+	}
+	ends := set.FromSlice(j.Days(partition.EndDates()))
+	return &Processor{
+		DayEnd: func(d *Day) error {
+			if ends.Has(d) {
+				f(d.Date, d.Normalized)
+			}
+			return nil
+		},
+	}
+}
+
+// ApplySplits books the change of the positions in a commodity on the day of
+// its split, against the valuation account, and adds prices adjusted by the
+// split ratio where the day has none, so that positions keep their value.
+// The checker adjusts positions for splits itself, so ApplySplits must run
+// after check and before ComputePrices.
+func ApplySplits(reg *model.Registry) *Processor {
+	type pair struct{ commodity, target *model.Commodity }
+	quantities := make(amounts.Amounts)
+	prices := make(map[pair]*model.Price)
+
+	return &Processor{
+		DayStart: func(d *Day) error {
+			for _, s := range d.Splits {
+				priced := set.New[pair]()
+				for _, p := range d.Prices {
+					priced.Add(pair{p.Commodity, p.Target})
+				}
+				var adjusted []*model.Price
+				for k, p := range prices {
+					if priced.Has(k) {
+						continue
+					}
+					switch s.Commodity {
+					case k.commodity:
+						adjusted = append(adjusted, &model.Price{Date: d.Date, Commodity: k.commodity, Price: s.Price(p.Price), Target: k.target})
+					case k.target:
+						adjusted = append(adjusted, &model.Price{Date: d.Date, Commodity: k.commodity, Price: s.Quantity(p.Price), Target: k.target})
+					}
+				}
+				compare.Sort(adjusted, func(p1, p2 *model.Price) compare.Order {
+					if o := commodity.Compare(p1.Commodity, p2.Commodity); o != compare.Equal {
+						return o
+					}
+					return commodity.Compare(p1.Target, p2.Target)
+				})
+				d.Prices = append(d.Prices, adjusted...)
+
+				for _, pos := range quantities.Index(compareFlows) {
+					qty := quantities[pos]
+					if pos.Commodity != s.Commodity || qty.IsZero() {
+						continue
+					}
+					d.Transactions = append(d.Transactions, transaction.Builder{
+						Date:        d.Date,
+						Description: fmt.Sprintf("Split %s %s:%s in account %s", s.Commodity.Name(), s.Numerator, s.Denominator, pos.Account.Name()),
+						Postings: posting.Builder{
+							Credit:    reg.Accounts().ValuationAccountFor(pos.Account),
+							Debit:     pos.Account,
+							Commodity: pos.Commodity,
+							Quantity:  s.Quantity(qty).Sub(qty),
+						}.Build(),
+						Targets: []*model.Commodity{pos.Commodity},
+					}.Build())
+				}
+			}
+			return nil
+		},
+
+		Price: func(p *model.Price) error {
+			prices[pair{p.Commodity, p.Target}] = p
+			return nil
+		},
+
+		Posting: func(_ *model.Transaction, p *model.Posting) error {
+			if p.Account.IsAL() {
+				quantities.Add(amounts.AccountCommodityKey(p.Account, p.Commodity), p.Quantity)
+			}
+			return nil
+		},
+	}
+}
+
 // Balance balances the journal.
 func Valuate(reg *model.Registry, valuation *model.Commodity) *Processor {
+	return Valuator{Registry: reg, Valuation: valuation}.Process()
+}
+
+// ValuateOrSkip is like Valuate, but if skip is not nil, commodities without
+// a price are valued at zero and reported to skip instead of failing.
+func ValuateOrSkip(reg *model.Registry, valuation *model.Commodity, skip func(*model.Commodity)) *Processor {
+	return Valuator{Registry: reg, Valuation: valuation, Skip: skip}.Process()
+}
+
+// DefaultMemo is the template for the description of valuation transactions.
+var DefaultMemo = template.Must(template.New("memo").Parse("Adjust value of {{.Commodity}} in account {{.Account}}"))
+
+// Memo holds the data available to the template for the description
+// of a valuation transaction.
+type Memo struct {
+	Date               string
+	Commodity, Account string
+	Gain               decimal.Decimal
+}
+
+// Valuator values postings in the valuation commodity and books the
+// changes in value of the positions as transactions.
+type Valuator struct {
+	Registry  *model.Registry
+	Valuation *model.Commodity
+
+	// Skip, if not nil, receives commodities without a price, which are
+	// then valued at zero instead of failing.
+	Skip func(*model.Commodity)
+
+	// Memo is the template for the description of valuation transactions.
+	// If nil, DefaultMemo is used.
+	Memo *template.Template
+}
+
+func (v Valuator) Process() *Processor {
+	reg, valuation, skip := v.Registry, v.Valuation, v.Skip
 	if valuation == nil {
 		return nil
 	}
+	memo := v.Memo
+	if memo == nil {
+		memo = DefaultMemo
+	}
 
 	var prevPrices, prices price.NormalizedPrices
 	quantities := make(amounts.Amounts)
 
+	priceOf := func(np price.NormalizedPrices, c *model.Commodity) (decimal.Decimal, error) {
+		p, err := np.Price(c)
+		if err != nil && skip != nil {
+			skip(c)
+			return decimal.Zero, nil
+		}
+		return p, err
+	}
+
 	return &Processor{
 
 		DayStart: func(d *Day) error {
@@ -62,11 +235,11 @@ func Valuate(reg *model.Registry, valuation *model.Commodity) *Processor {
 				if qty.IsZero() {
 					continue
 				}
-				prevPrice, err := prevPrices.Price(pos.Commodity)
+				prevPrice, err := priceOf(prevPrices, pos.Commodity)
 				if err != nil {
 					return err
 				}
-				currentPrice, err := prices.Price(pos.Commodity)
+				currentPrice, err := priceOf(prices, pos.Commodity)
 				if err != nil {
 					return err
 				}
@@ -76,9 +249,19 @@ func Valuate(reg *model.Registry, valuation *model.Commodity) *Processor {
 				}
 				gain := price.Multiply(delta, qty)
 				credit := reg.Accounts().ValuationAccountFor(pos.Account)
+				var desc strings.Builder
+				err = memo.Execute(&desc, Memo{
+					Date:      d.Date.Format("2006-01-02"),
+					Commodity: pos.Commodity.Name(),
+					Account:   pos.Account.Name(),
+					Gain:      gain,
+				})
+				if err != nil {
+					return err
+				}
 				d.Transactions = append(d.Transactions, transaction.Builder{
 					Date:        d.Date,
-					Description: fmt.Sprintf("Adjust value of %s in account %s", pos.Commodity.Name(), pos.Account.Name()),
+					Description: desc.String(),
 					Postings: posting.Builder{
 						Credit:    credit,
 						Debit:     pos.Account,
@@ -91,7 +274,7 @@ func Valuate(reg *model.Registry, valuation *model.Commodity) *Processor {
 			return nil
 		},
 
-		Posting: func(_ *model.Transaction, p *model.Posting) error {
+		Posting: func(t *model.Transaction, p *model.Posting) error {
 			if p.Quantity.IsZero() {
 				return nil
 			}
@@ -102,11 +285,11 @@ func Valuate(reg *model.Registry, valuation *model.Commodity) *Processor {
 				p.Value = p.Quantity
 				return nil
 			}
-			v, err := prices.Valuate(p.Commodity, p.Quantity)
+			prc, err := priceOf(prices, p.Commodity)
 			if err != nil {
-				return err
+				return fmt.Errorf("%s: cannot value %s %s in account %s: %w", t.Date.Format("2006-01-02"), p.Quantity, p.Commodity.Name(), p.Account.Name(), err)
 			}
-			p.Value = v
+			p.Value = price.Multiply(p.Quantity, prc)
 			return nil
 		},
 
@@ -120,7 +303,7 @@ func Valuate(reg *model.Registry, valuation *model.Commodity) *Processor {
 func Filter(part date.Partition) *Processor {
 	return &Processor{
 		DayEnd: func(d *Day) error {
-			if !part.Contains(d.Date) {
+			if !part.Retains(d.Date) {
 				d.Transactions = nil
 			}
 			return nil
@@ -128,6 +311,84 @@ func Filter(part date.Partition) *Processor {
 	}
 }
 
+// FoldTransfers removes the transactions whose postings are all in accounts
+// matching the given regexes, such as transfers between own accounts. As
+// positions change, it must run after check and valuation.
+func FoldTransfers(internal regex.Regexes) *Processor {
+	if len(internal) == 0 {
+		return nil
+	}
+	isInternal := predicate.ByName[*model.Account](internal)
+	return &Processor{
+		DayEnd: func(d *Day) error {
+			var keep []*model.Transaction
+			for _, t := range d.Transactions {
+				if !slices.ContainsFunc(t.Postings, func(p *model.Posting) bool { return !isInternal(p.Account) }) {
+					continue
+				}
+				keep = append(keep, t)
+			}
+			d.Transactions = keep
+			return nil
+		},
+	}
+}
+
+// Slice limits the journal to the directives between two marker
+// transactions, both included. A nil From starts at the beginning of the
+// journal, a nil To continues until its end. Transactions must be sorted.
+type Slice struct {
+	From, To predicate.Predicate[*model.Transaction]
+
+	started, done bool
+}
+
+// Process returns a processor which removes the directives outside of
+// the slice.
+func (s *Slice) Process() *Processor {
+	s.started = s.From == nil
+	return &Processor{
+		DayEnd: func(d *Day) error {
+			active := s.started && !s.done
+			var ts []*model.Transaction
+			for _, t := range d.Transactions {
+				if s.done {
+					break
+				}
+				if !s.started && s.From(t) {
+					s.started = true
+				}
+				if !s.started {
+					continue
+				}
+				ts = append(ts, t)
+				if s.To != nil && s.To(t) {
+					s.done = true
+				}
+			}
+			d.Transactions = ts
+			if !active && len(ts) == 0 {
+				d.Prices = nil
+				d.Assertions = nil
+				d.Openings = nil
+				d.Closings = nil
+			}
+			return nil
+		},
+	}
+}
+
+// Err returns an error if one of the markers has not been found.
+func (s *Slice) Err() error {
+	if !s.started {
+		return fmt.Errorf("start marker transaction not found")
+	}
+	if s.To != nil && !s.done {
+		return fmt.Errorf("end marker transaction not found")
+	}
+	return nil
+}
+
 // Balance balances the journal.
 func CloseAccounts(j *Builder, reg *model.Registry, enable bool, partition date.Partition) *Processor {
 	if !enable {
@@ -175,6 +436,110 @@ func CloseAccounts(j *Builder, reg *model.Registry, enable bool, partition date.
 	}
 }
 
+// Consolidate replaces the transactions matching the predicate with one
+// aggregated transaction per period of the partition, dated at the end of
+// the period. If an account with consolidated flows is asserted or closed
+// before, the flows up to then are booked on that day instead, so that the
+// assertions still hold. The net flows between each pair of accounts are
+// preserved.
+// Replaced transactions are passed to the given function, if not nil.
+func Consolidate(j *Builder, partition date.Partition, match predicate.Predicate[*model.Transaction], replaced func(*model.Transaction) error) *Processor {
+	closingDays := set.FromSlice(j.Days(partition.EndDates()))
+	quantities := make(amounts.Amounts)
+	var count int
+
+	return &Processor{
+		DayEnd: func(d *Day) error {
+			if partition.Contains(d.Date) {
+				var keep []*model.Transaction
+				for _, t := range d.Transactions {
+					if !match(t) {
+						keep = append(keep, t)
+						continue
+					}
+					for i := 0; i+1 < len(t.Postings); i += 2 {
+						credit, debit := t.Postings[i], t.Postings[i+1]
+						key := amounts.Key{Account: debit.Account, Other: credit.Account, Commodity: debit.Commodity}
+						quantity := debit.Quantity
+						if account.Compare(key.Account, key.Other) == compare.Smaller {
+							key.Account, key.Other, quantity = key.Other, key.Account, quantity.Neg()
+						}
+						quantities.Add(key, quantity)
+					}
+					count++
+					if replaced != nil {
+						if err := replaced(t); err != nil {
+							return err
+						}
+					}
+				}
+				d.Transactions = keep
+			}
+			if count == 0 || !closingDays.Has(d) && !checksFlow(d, quantities) {
+				return nil
+			}
+			var postings posting.Builders
+			for _, k := range quantities.Index(compareFlows) {
+				if quantities[k].IsZero() {
+					continue
+				}
+				postings = append(postings, posting.Builder{
+					Credit:    k.Other,
+					Debit:     k.Account,
+					Commodity: k.Commodity,
+					Quantity:  quantities[k],
+				})
+			}
+			if len(postings) > 0 {
+				d.Transactions = append(d.Transactions, transaction.Builder{
+					Date:        d.Date,
+					Description: consolidatedDescription(count),
+					Postings:    postings.Build(),
+				}.Build())
+			}
+			quantities, count = make(amounts.Amounts), 0
+			return nil
+		},
+	}
+}
+
+func consolidatedDescription(count int) string {
+	if count == 1 {
+		return "Consolidated 1 transaction"
+	}
+	return fmt.Sprintf("Consolidated %d transactions", count)
+}
+
+// checksFlow returns whether an account with a flow is asserted or closed
+// on the day.
+func checksFlow(d *Day, flows amounts.Amounts) bool {
+	var accounts []*model.Account
+	for _, c := range d.Closings {
+		accounts = append(accounts, c.Account)
+	}
+	for _, a := range d.Assertions {
+		for _, bal := range a.Balances {
+			accounts = append(accounts, bal.Account)
+		}
+	}
+	for k := range flows {
+		if slices.Contains(accounts, k.Account) || slices.Contains(accounts, k.Other) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareFlows(k1, k2 amounts.Key) compare.Order {
+	if o := account.Compare(k1.Account, k2.Account); o != compare.Equal {
+		return o
+	}
+	if o := account.Compare(k1.Other, k2.Other); o != compare.Equal {
+		return o
+	}
+	return commodity.Compare(k1.Commodity, k2.Commodity)
+}
+
 // Sort sorts the directives in this day.
 func Sort() *Processor {
 	return &Processor{
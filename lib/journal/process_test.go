@@ -0,0 +1,228 @@
+package journal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/posting"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/model/transaction"
+)
+
+func TestApplySplits(t *testing.T) {
+	var (
+		reg    = registry.New()
+		aapl   = reg.Commodities().MustGet("AAPL")
+		usd    = reg.Commodities().MustGet("USD")
+		chf    = reg.Commodities().MustGet("CHF")
+		broker = reg.Accounts().MustGet("Assets:Broker")
+		equity = reg.Accounts().MustGet("Equity:Opening")
+	)
+	tests := []struct {
+		desc string
+		// price is an additional price on the day of the split.
+		price      *model.Price
+		wantPrices []string
+	}{
+		{
+			desc: "adjusts prices of and in the commodity",
+			wantPrices: []string{
+				"AAPL 50 USD",
+				"CHF 2 AAPL",
+			},
+		},
+		{
+			desc:  "keeps a price given on the day of the split",
+			price: &model.Price{Date: date.Date(2020, 1, 3), Commodity: aapl, Price: decimal.NewFromInt(60), Target: usd},
+			wantPrices: []string{
+				"AAPL 60 USD",
+				"CHF 2 AAPL",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			b := New()
+			b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: broker})
+			b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: equity})
+			b.Add(transaction.Builder{
+				Date:        date.Date(2020, 1, 1),
+				Description: "opening",
+				Postings: posting.Builder{
+					Credit:    equity,
+					Debit:     broker,
+					Commodity: aapl,
+					Quantity:  decimal.NewFromInt(10),
+				}.Build(),
+			}.Build())
+			b.Add(&model.Price{Date: date.Date(2020, 1, 2), Commodity: aapl, Price: decimal.NewFromInt(100), Target: usd})
+			b.Add(&model.Price{Date: date.Date(2020, 1, 2), Commodity: chf, Price: decimal.NewFromInt(1), Target: aapl})
+			b.Add(&model.Split{Date: date.Date(2020, 1, 3), Commodity: aapl, Numerator: decimal.NewFromInt(2), Denominator: decimal.NewFromInt(1)})
+			if test.price != nil {
+				b.Add(test.price)
+			}
+			j := b.Build()
+
+			if err := j.Process(ApplySplits(reg)); err != nil {
+				t.Fatalf("Process(): unexpected error %v", err)
+			}
+
+			day := j.Days[len(j.Days)-1]
+			var gotPrices []string
+			for _, p := range day.Prices {
+				gotPrices = append(gotPrices, p.Commodity.Name()+" "+p.Price.String()+" "+p.Target.Name())
+			}
+			if diff := cmp.Diff(test.wantPrices, gotPrices); diff != "" {
+				t.Errorf("prices: unexpected diff (+got/-want):\n%s", diff)
+			}
+			if len(day.Transactions) != 1 {
+				t.Fatalf("got %d transactions, want 1", len(day.Transactions))
+			}
+			var gotQuantity decimal.Decimal
+			for _, p := range day.Transactions[0].Postings {
+				if p.Account == broker && p.Commodity == aapl {
+					gotQuantity = gotQuantity.Add(p.Quantity)
+				}
+			}
+			if want := decimal.NewFromInt(10); !gotQuantity.Equal(want) {
+				t.Errorf("split booking: got %s AAPL in %s, want %s", gotQuantity, broker.Name(), want)
+			}
+		})
+	}
+}
+
+func TestApplySplitsIgnoresOtherCommodities(t *testing.T) {
+	var (
+		reg    = registry.New()
+		aapl   = reg.Commodities().MustGet("AAPL")
+		msft   = reg.Commodities().MustGet("MSFT")
+		broker = reg.Accounts().MustGet("Assets:Broker")
+		equity = reg.Accounts().MustGet("Equity:Opening")
+	)
+	b := New()
+	b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: broker})
+	b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: equity})
+	b.Add(transaction.Builder{
+		Date:        date.Date(2020, 1, 1),
+		Description: "opening",
+		Postings: posting.Builder{
+			Credit:    equity,
+			Debit:     broker,
+			Commodity: msft,
+			Quantity:  decimal.NewFromInt(10),
+		}.Build(),
+	}.Build())
+	b.Add(&model.Split{Date: date.Date(2020, 1, 3), Commodity: aapl, Numerator: decimal.NewFromInt(2), Denominator: decimal.NewFromInt(1)})
+	j := b.Build()
+
+	if err := j.Process(ApplySplits(reg)); err != nil {
+		t.Fatalf("Process(): unexpected error %v", err)
+	}
+
+	day := j.Days[len(j.Days)-1]
+	if len(day.Transactions) != 0 || len(day.Prices) != 0 {
+		t.Fatalf("got %d transactions and %d prices, want none", len(day.Transactions), len(day.Prices))
+	}
+}
+
+func TestConsolidate(t *testing.T) {
+	var (
+		reg       = registry.New()
+		chf       = reg.Commodities().MustGet("CHF")
+		bank      = reg.Accounts().MustGet("Assets:Bank")
+		groceries = reg.Accounts().MustGet("Expenses:Groceries")
+		rent      = reg.Accounts().MustGet("Expenses:Rent")
+		partition = date.NewPartition(date.Period{Start: date.Date(2020, 1, 1), End: date.Date(2020, 1, 31)}, date.Monthly, 0)
+	)
+	tests := []struct {
+		desc      string
+		directive model.Directive
+		want      []string
+	}{
+		{
+			desc: "books at the end of the period",
+			want: []string{
+				"2020-01-31 Consolidated 3 transactions: Expenses:Groceries 30 CHF, Expenses:Rent 30 CHF",
+			},
+		},
+		{
+			desc:      "books before a closing",
+			directive: &model.Close{Date: date.Date(2020, 1, 15), Account: groceries},
+			want: []string{
+				"2020-01-15 Consolidated 2 transactions: Expenses:Groceries 30 CHF",
+				"2020-01-31 Consolidated 1 transaction: Expenses:Rent 30 CHF",
+			},
+		},
+		{
+			desc: "books before an assertion",
+			directive: &model.Assertion{Date: date.Date(2020, 1, 7), Balances: []model.Balance{
+				{Account: bank, Commodity: chf, Quantity: decimal.NewFromInt(-10)},
+			}},
+			want: []string{
+				"2020-01-07 Consolidated 1 transaction: Expenses:Groceries 10 CHF",
+				"2020-01-31 Consolidated 2 transactions: Expenses:Groceries 20 CHF, Expenses:Rent 30 CHF",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			b := New()
+			for _, a := range []*model.Account{bank, groceries, rent} {
+				b.Add(&model.Open{Date: date.Date(2020, 1, 1), Account: a})
+			}
+			book := func(d int, debit *model.Account, q int64) {
+				b.Add(transaction.Builder{
+					Date:        date.Date(2020, 1, d),
+					Description: "booking",
+					Postings: posting.Builder{
+						Credit:    bank,
+						Debit:     debit,
+						Commodity: chf,
+						Quantity:  decimal.NewFromInt(q),
+					}.Build(),
+				}.Build())
+			}
+			book(5, groceries, 10)
+			book(10, groceries, 20)
+			book(20, rent, 30)
+			if test.directive != nil {
+				b.Add(test.directive)
+			}
+			var replaced int
+			consolidate := Consolidate(b, partition, func(*model.Transaction) bool { return true }, func(*model.Transaction) error {
+				replaced++
+				return nil
+			})
+			j := b.Build()
+
+			if err := j.Process(consolidate); err != nil {
+				t.Fatalf("Process(): unexpected error %v", err)
+			}
+
+			var got []string
+			for _, d := range j.Days {
+				for _, trx := range d.Transactions {
+					var flows []string
+					for _, p := range trx.Postings {
+						if p.Account != bank {
+							flows = append(flows, fmt.Sprintf("%s %s %s", p.Account.Name(), p.Quantity, p.Commodity.Name()))
+						}
+					}
+					got = append(got, fmt.Sprintf("%s %s: %s", trx.Date.Format("2006-01-02"), trx.Description, strings.Join(flows, ", ")))
+				}
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("transactions: unexpected diff (-want/+got):\n%s", diff)
+			}
+			if replaced != 3 {
+				t.Errorf("got %d replaced transactions, want 3", replaced)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package account
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -30,6 +31,7 @@ type Registry struct {
 	index    map[string]*Account
 	accounts *multimap.Node[*Account]
 	swaps    map[*Account]*Account
+	aliases  map[string]*Account
 }
 
 // NewRegistry creates a new thread-safe collection of accounts.
@@ -38,6 +40,7 @@ func NewRegistry() *Registry {
 		accounts: multimap.New[*Account](""),
 		index:    make(map[string]*Account),
 		swaps:    make(map[*Account]*Account),
+		aliases:  make(map[string]*Account),
 	}
 	for _, t := range types {
 		reg.Get(t.String())
@@ -124,8 +127,47 @@ func (as *Registry) MustGetPath(ss []string) *Account {
 	return res
 }
 
+// Create returns the account referenced by the given syntax element,
+// resolving aliases.
 func (as *Registry) Create(a syntax.Account) (*Account, error) {
-	return as.Get(a.Extract())
+	name := a.Extract()
+	as.mutex.RLock()
+	res, ok := as.aliases[name]
+	as.mutex.RUnlock()
+	if ok {
+		return res, nil
+	}
+	res, err := as.Get(name)
+	if err != nil {
+		if _, isType := types[name]; !isType && !strings.Contains(name, ":") {
+			err = ErrUndefinedAlias
+		}
+		return nil, syntax.Error{Range: a.Range, Message: fmt.Sprintf("parsing account %q", name), Wrapped: err}
+	}
+	return res, nil
+}
+
+// ErrUndefinedAlias is returned by Create for a name which is neither an
+// account nor an alias.
+var ErrUndefinedAlias = errors.New("undefined alias")
+
+// CreateAlias registers the alias defined by the given syntax element.
+func (as *Registry) CreateAlias(a syntax.Alias) error {
+	name := a.Name.Extract()
+	if _, isType := types[name]; isType {
+		return syntax.Error{Range: a.Name, Message: fmt.Sprintf("alias %q conflicts with an account type", name)}
+	}
+	account, err := as.Get(a.Account.Extract())
+	if err != nil {
+		return syntax.Error{Range: a.Account.Range, Message: "parsing account", Wrapped: err}
+	}
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	if existing, ok := as.aliases[name]; ok && existing != account {
+		return syntax.Error{Range: a.Range, Message: fmt.Sprintf("alias %q is already defined for account %s", name, existing)}
+	}
+	as.aliases[name] = account
+	return nil
 }
 
 func isValidSegment(s string) bool {
@@ -172,9 +214,12 @@ func (as *Registry) SwapType(a *Account) *Account {
 	return sw
 }
 
+// TBD is the name of the account for postings which remain to be categorized.
+const TBD = "Expenses:TBD"
+
 // TBDAccount returns the TBD account.
 func (as *Registry) TBDAccount() *Account {
-	return as.MustGet("Expenses:TBD")
+	return as.MustGet(TBD)
 }
 
 // ValuationAccountFor returns the valuation account which corresponds to
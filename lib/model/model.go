@@ -2,7 +2,9 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/sboehler/knut/lib/common/cpr"
 	"github.com/sboehler/knut/lib/model/account"
@@ -13,6 +15,7 @@ import (
 	"github.com/sboehler/knut/lib/model/posting"
 	"github.com/sboehler/knut/lib/model/price"
 	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/model/split"
 	"github.com/sboehler/knut/lib/model/transaction"
 	"github.com/sboehler/knut/lib/syntax"
 	"github.com/sourcegraph/conc/pool"
@@ -28,6 +31,7 @@ type Close = cls.Close
 type Price = price.Price
 type Assertion = assertion.Assertion
 type Balance = assertion.Balance
+type Split = split.Split
 
 type Registry = registry.Registry
 
@@ -38,6 +42,7 @@ var (
 	_ Directive = (*cls.Close)(nil)
 	_ Directive = (*open.Open)(nil)
 	_ Directive = (*price.Price)(nil)
+	_ Directive = (*split.Split)(nil)
 	_ Directive = (*transaction.Transaction)(nil)
 )
 
@@ -48,12 +53,27 @@ type Result struct {
 
 func FromStream(reg *registry.Registry, inCh <-chan syntax.File) (<-chan []Directive, func(context.Context) error) {
 	return cpr.Produce(func(ctx context.Context, ch chan<- []Directive) error {
+		// Aliases may be declared in any file. Directives using an alias
+		// which is not registered yet are deferred until all files are read.
+		var (
+			mutex    sync.Mutex
+			deferred []syntax.Directive
+		)
 		wg := pool.New().WithContext(ctx).WithCancelOnError().WithFirstError()
 		cpr.ForEach(ctx, inCh, func(input syntax.File) error {
 			wg.Go(func(ctx context.Context) error {
+				if err := registerSettings(reg, input); err != nil {
+					return err
+				}
 				var ds []Directive
 				for _, d := range input.Directives {
 					m, err := ParseDirective(reg, d)
+					if errors.Is(err, account.ErrUndefinedAlias) {
+						mutex.Lock()
+						deferred = append(deferred, d)
+						mutex.Unlock()
+						continue
+					}
 					if err != nil {
 						return err
 					}
@@ -63,10 +83,37 @@ func FromStream(reg *registry.Registry, inCh <-chan syntax.File) (<-chan []Direc
 			})
 			return nil
 		})
-		return wg.Wait()
+		if err := wg.Wait(); err != nil {
+			return err
+		}
+		var ds []Directive
+		for _, d := range deferred {
+			m, err := ParseDirective(reg, d)
+			if err != nil {
+				return err
+			}
+			ds = append(ds, m...)
+		}
+		return cpr.Push(ctx, ch, ds)
 	})
 }
 
+func registerSettings(reg *registry.Registry, f syntax.File) error {
+	for _, d := range f.Directives {
+		switch s := d.Directive.(type) {
+		case syntax.Alias:
+			if err := reg.Accounts().CreateAlias(s); err != nil {
+				return err
+			}
+		case syntax.Option:
+			if err := reg.Options().Set(s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func ParseDirective(reg *registry.Registry, w syntax.Directive) ([]Directive, error) {
 	switch d := w.Directive.(type) {
 	case syntax.Transaction:
@@ -103,7 +150,13 @@ func ParseDirective(reg *registry.Registry, w syntax.Directive) ([]Directive, er
 			return nil, err
 		}
 		return []Directive{o}, nil
-	case syntax.Include:
+	case syntax.Split:
+		o, err := split.Create(reg, &d)
+		if err != nil {
+			return nil, err
+		}
+		return []Directive{o}, nil
+	case syntax.Include, syntax.Alias, syntax.Option:
 		return nil, nil
 	}
 	return nil, fmt.Errorf("unknown directive: %T", w)
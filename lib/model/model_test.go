@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sboehler/knut/lib/common/cpr"
+	"github.com/sboehler/knut/lib/model/account"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/syntax"
+	"github.com/sboehler/knut/lib/syntax/parser"
+	"github.com/sourcegraph/conc/pool"
+)
+
+func fromTexts(t *testing.T, reg *registry.Registry, texts ...string) ([]Directive, error) {
+	t.Helper()
+	var files []syntax.File
+	for _, text := range texts {
+		p := parser.New(text, "")
+		if err := p.Advance(); err != nil {
+			t.Fatalf("Advance(): unexpected error %v", err)
+		}
+		f, err := p.ParseFile()
+		if err != nil {
+			t.Fatalf("ParseFile(): unexpected error %v", err)
+		}
+		files = append(files, f)
+	}
+	inCh, worker1 := cpr.Produce(func(ctx context.Context, ch chan<- syntax.File) error {
+		return cpr.Push(ctx, ch, files...)
+	})
+	modelCh, worker2 := FromStream(reg, inCh)
+	var res []Directive
+	p := pool.New().WithErrors().WithFirstError().WithContext(context.Background())
+	p.Go(worker1)
+	p.Go(worker2)
+	p.Go(func(ctx context.Context) error {
+		return cpr.ForEach(ctx, modelCh, func(ds []Directive) error {
+			res = append(res, ds...)
+			return nil
+		})
+	})
+	return res, p.Wait()
+}
+
+func TestFromStreamResolvesAliasesOfLaterFiles(t *testing.T) {
+	reg := registry.New()
+
+	got, err := fromTexts(t, reg,
+		"2020-01-01 open co\n",
+		"alias co = Assets:Bank:Checking\n",
+	)
+
+	if err != nil {
+		t.Fatalf("FromStream(): unexpected error %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d directives, want 1", len(got))
+	}
+	if o, ok := got[0].(*Open); !ok || o.Account != reg.Accounts().MustGet("Assets:Bank:Checking") {
+		t.Fatalf("got %v, want an open directive for Assets:Bank:Checking", got[0])
+	}
+}
+
+func TestFromStreamRejectsUndefinedAliases(t *testing.T) {
+	_, err := fromTexts(t, registry.New(), "2020-01-01 open co\n")
+
+	if !errors.Is(err, account.ErrUndefinedAlias) {
+		t.Fatalf("FromStream() returned %v, want %v", err, account.ErrUndefinedAlias)
+	}
+}
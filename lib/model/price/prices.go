@@ -43,19 +43,45 @@ func (ps Prices) addPrice(target, commodity *commodity.Commodity, price decimal.
 // Normalize creates a normalized price map for the given commodity.
 func (ps Prices) Normalize(t *commodity.Commodity) NormalizedPrices {
 	res := NormalizedPrices{t: one}
-	ps.normalize(t, res)
+	ps.normalize(t, res, nil)
 	return res
 }
 
-// normalize recursively computes prices by traversing the price graph.
-// res must already contain a price for c.
-func (ps Prices) normalize(c *commodity.Commodity, res NormalizedPrices) {
-	for neighbor, price := range ps[c] {
+// Path returns the commodities through which Normalize derives the
+// price of c in t, starting with t and ending with c.
+func (ps Prices) Path(t, c *commodity.Commodity) ([]*commodity.Commodity, bool) {
+	res := NormalizedPrices{t: one}
+	via := make(map[*commodity.Commodity]*commodity.Commodity)
+	ps.normalize(t, res, via)
+	if _, ok := res[c]; !ok {
+		return nil, false
+	}
+	path := []*commodity.Commodity{c}
+	for c != t {
+		c = via[c]
+		path = append(path, c)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, true
+}
+
+// normalize recursively computes prices by traversing the price graph,
+// visiting the neighbors of each commodity in order, so that the result
+// does not depend on map iteration. res must already contain a price
+// for c. If via is not nil, it receives the predecessor of each
+// commodity reached.
+func (ps Prices) normalize(c *commodity.Commodity, res NormalizedPrices, via map[*commodity.Commodity]*commodity.Commodity) {
+	for _, neighbor := range dict.SortedKeys(ps[c], commodity.Compare) {
 		if _, done := res[neighbor]; done {
 			continue
 		}
-		res[neighbor] = Multiply(price, res[c])
-		ps.normalize(neighbor, res)
+		res[neighbor] = Multiply(ps[c][neighbor], res[c])
+		if via != nil {
+			via[neighbor] = c
+		}
+		ps.normalize(neighbor, res, via)
 	}
 }
 
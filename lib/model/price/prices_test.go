@@ -134,3 +134,81 @@ func TestNormalize(t *testing.T) {
 		})
 	}
 }
+
+func TestPath(t *testing.T) {
+	reg := registry.New()
+	com1 := reg.Commodities().MustGet("COM1")
+	com2 := reg.Commodities().MustGet("COM2")
+	com3 := reg.Commodities().MustGet("COM3")
+	com4 := reg.Commodities().MustGet("COM4")
+
+	tests := []struct {
+		desc   string
+		input  []*Price
+		target *commodity.Commodity
+		c      *commodity.Commodity
+		want   []string
+		wantOK bool
+	}{
+		{
+			desc:   "target",
+			target: com1,
+			c:      com1,
+			want:   []string{"COM1"},
+			wantOK: true,
+		},
+		{
+			desc: "chain",
+			input: []*Price{
+				{Commodity: com1, Price: decimal.RequireFromString("4.0"), Target: com2},
+				{Commodity: com2, Price: decimal.RequireFromString("2.0"), Target: com3},
+			},
+			target: com3,
+			c:      com1,
+			want:   []string{"COM3", "COM2", "COM1"},
+			wantOK: true,
+		},
+		{
+			desc: "follows normalize through the first neighbor",
+			input: []*Price{
+				{Commodity: com1, Price: decimal.RequireFromString("4.0"), Target: com3},
+				{Commodity: com2, Price: decimal.RequireFromString("2.0"), Target: com3},
+				{Commodity: com1, Price: decimal.RequireFromString("3.0"), Target: com2},
+			},
+			target: com3,
+			c:      com1,
+			want:   []string{"COM3", "COM1"},
+			wantOK: true,
+		},
+		{
+			desc: "unreachable",
+			input: []*Price{
+				{Commodity: com1, Price: decimal.RequireFromString("4.0"), Target: com2},
+			},
+			target: com3,
+			c:      com4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			pr := make(Prices)
+			for _, in := range test.input {
+				pr.Insert(in.Commodity, in.Price, in.Target)
+			}
+
+			got, ok := pr.Path(test.target, test.c)
+
+			if ok != test.wantOK {
+				t.Fatalf("Path() returned ok=%t, want %t", ok, test.wantOK)
+			}
+			var names []string
+			for _, c := range got {
+				names = append(names, c.Name())
+			}
+			if diff := cmp.Diff(test.want, names); diff != "" {
+				t.Fatalf("unexpected diff (-want/+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/model/commodity"
+	"github.com/sboehler/knut/lib/syntax"
+)
+
+// The option keys recognized in option directives.
+const (
+	// Valuation is the default valuation commodity, for example "CHF".
+	Valuation = "valuation"
+	// Interval is the default interval of reports, for example "monthly".
+	Interval = "interval"
+	// Precision is the default number of digits to round reports to.
+	Precision = "precision"
+	// FiscalYearStart is the month in which the fiscal year begins,
+	// from "1" to "12".
+	FiscalYearStart = "fiscal-year-start"
+)
+
+// Options is a thread-safe collection of the settings declared
+// by option directives.
+type Options struct {
+	commodities *commodity.Registry
+	values      map[string]option
+	unknown     []error
+	mutex       sync.RWMutex
+}
+
+type option struct {
+	syntax.Option
+	value any
+}
+
+func newOptions(commodities *commodity.Registry) *Options {
+	return &Options{
+		commodities: commodities,
+		values:      make(map[string]option),
+	}
+}
+
+func (opts *Options) parse(key, value string) (any, bool, error) {
+	switch key {
+	case Valuation:
+		c, err := opts.commodities.Get(value)
+		return c, true, err
+	case Interval:
+		i, err := date.ParseInterval(value)
+		return i, true, err
+	case Precision:
+		p, err := strconv.ParseUint(value, 10, 31)
+		return int32(p), true, err
+	case FiscalYearStart:
+		m, err := strconv.Atoi(value)
+		if err == nil && (m < 1 || m > 12) {
+			err = fmt.Errorf("invalid month %d, want 1 to 12", m)
+		}
+		return time.Month(m), true, err
+	}
+	return nil, false, nil
+}
+
+// Set registers the option defined by the given syntax element. Options
+// with unknown keys are ignored and reported by Unknown.
+func (opts *Options) Set(o syntax.Option) error {
+	key, value := o.Key.Content.Extract(), o.Value.Content.Extract()
+	v, known, err := opts.parse(key, value)
+	opts.mutex.Lock()
+	defer opts.mutex.Unlock()
+	if !known {
+		opts.unknown = append(opts.unknown, syntax.Error{Range: o.Key.Range, Message: fmt.Sprintf("unknown option %q", key)})
+		return nil
+	}
+	if err != nil {
+		return syntax.Error{Range: o.Value.Range, Message: fmt.Sprintf("parsing option %q", key), Wrapped: err}
+	}
+	if existing, ok := opts.values[key]; ok && existing.Value.Content.Extract() != value {
+		return syntax.Error{Range: o.Range, Message: fmt.Sprintf("option %q is already set to %q", key, existing.Value.Content.Extract())}
+	}
+	opts.values[key] = option{o, v}
+	return nil
+}
+
+// Unknown returns an error for each option with an unknown key.
+func (opts *Options) Unknown() []error {
+	opts.mutex.RLock()
+	defer opts.mutex.RUnlock()
+	return opts.unknown
+}
+
+func (opts *Options) get(key string) (any, bool) {
+	opts.mutex.RLock()
+	defer opts.mutex.RUnlock()
+	o, ok := opts.values[key]
+	return o.value, ok
+}
+
+// Valuation returns the default valuation commodity.
+func (opts *Options) Valuation() (*commodity.Commodity, bool) {
+	v, ok := opts.get(Valuation)
+	if !ok {
+		return nil, false
+	}
+	return v.(*commodity.Commodity), true
+}
+
+// Interval returns the default interval.
+func (opts *Options) Interval() (date.Interval, bool) {
+	v, ok := opts.get(Interval)
+	if !ok {
+		return date.Once, false
+	}
+	return v.(date.Interval), true
+}
+
+// Precision returns the default number of digits.
+func (opts *Options) Precision() (int32, bool) {
+	v, ok := opts.get(Precision)
+	if !ok {
+		return 0, false
+	}
+	return v.(int32), true
+}
+
+// FiscalYearStart returns the month in which the fiscal year begins,
+// which is January by default.
+func (opts *Options) FiscalYearStart() time.Month {
+	v, ok := opts.get(FiscalYearStart)
+	if !ok {
+		return time.January
+	}
+	return v.(time.Month)
+}
@@ -23,17 +23,20 @@ type Account = account.Account
 type Commodity = commodity.Commodity
 
 // Registry has context for the model, namely a collection of
-// referenced accounts and commodities.
+// referenced accounts and commodities, and the journal options.
 type Registry struct {
 	accounts    *account.Registry
 	commodities *commodity.Registry
+	options     *Options
 }
 
 // New creates a new, empty context.
 func New() *Registry {
+	commodities := commodity.NewCommodities()
 	return &Registry{
 		accounts:    account.NewRegistry(),
-		commodities: commodity.NewCommodities(),
+		commodities: commodities,
+		options:     newOptions(commodities),
 	}
 }
 
@@ -46,3 +49,8 @@ func (reg Registry) Accounts() *account.Registry {
 func (reg Registry) Commodities() *commodity.Registry {
 	return reg.commodities
 }
+
+// Options returns the options.
+func (reg Registry) Options() *Options {
+	return reg.options
+}
@@ -0,0 +1,60 @@
+package split
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/model/commodity"
+	"github.com/sboehler/knut/lib/model/registry"
+	"github.com/sboehler/knut/lib/syntax"
+)
+
+// Split represents a stock split, where each Denominator units of the
+// commodity become Numerator units.
+type Split struct {
+	Src                    *syntax.Split
+	Date                   time.Time
+	Commodity              *commodity.Commodity
+	Numerator, Denominator decimal.Decimal
+}
+
+func Create(reg *registry.Registry, s *syntax.Split) (*Split, error) {
+	date, err := s.Date.Parse()
+	if err != nil {
+		return nil, err
+	}
+	com, err := reg.Commodities().Create(s.Commodity)
+	if err != nil {
+		return nil, err
+	}
+	num, err := s.Numerator.Parse()
+	if err != nil {
+		return nil, err
+	}
+	den, err := s.Denominator.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if !num.IsPositive() || !den.IsPositive() {
+		return nil, syntax.Error{Range: s.Range, Message: fmt.Sprintf("invalid split ratio %s:%s", num, den)}
+	}
+	return &Split{
+		Src:         s,
+		Date:        date,
+		Commodity:   com,
+		Numerator:   num,
+		Denominator: den,
+	}, nil
+}
+
+// Quantity returns the quantity after the split.
+func (s *Split) Quantity(q decimal.Decimal) decimal.Decimal {
+	return q.Mul(s.Numerator).Div(s.Denominator)
+}
+
+// Price returns the price after the split.
+func (s *Split) Price(p decimal.Decimal) decimal.Decimal {
+	return p.Mul(s.Denominator).Div(s.Numerator)
+}
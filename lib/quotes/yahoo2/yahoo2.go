@@ -40,11 +40,22 @@ type Quote struct {
 // Client is a client for Yahoo! quotes.
 type Client struct {
 	url string
+
+	// Timeout is the timeout for a single HTTP request. Zero means no timeout.
+	Timeout time.Duration
+
+	// Retries is the number of times a request is retried after a network
+	// error or a 5xx response.
+	Retries int
+
+	// Backoff is the delay before the first retry. It doubles with every
+	// further attempt.
+	Backoff time.Duration
 }
 
 // New creates a new client with the default URL.
 func New() Client {
-	return Client{yahooURL}
+	return Client{url: yahooURL, Backoff: time.Second}
 }
 
 // Fetch fetches a set of quotes
@@ -53,16 +64,37 @@ func (c *Client) Fetch(sym string, t0, t1 time.Time) ([]Quote, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error creating URL for symbol %s: %w", sym, err)
 	}
-	resp, err := http.Get(u.String())
+	client := http.Client{Timeout: c.Timeout}
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		quote, retry, err := c.fetch(&client, u)
+		if err == nil {
+			return quote, nil
+		}
+		if !retry || attempt >= c.Retries {
+			return nil, fmt.Errorf("error fetching data from URL %s: %w", u.String(), err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// fetch performs a single request. It reports whether the request
+// should be retried in case of an error.
+func (c *Client) fetch(client *http.Client, u *url.URL) ([]Quote, bool, error) {
+	resp, err := client.Get(u.String())
 	if err != nil {
-		return nil, fmt.Errorf("error fetching data from URL %s: %w", u.String(), err)
+		return nil, true, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode >= 500, fmt.Errorf("unexpected status %s", resp.Status)
+	}
 	quote, err := decodeResponse(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding response for symbol %s (url: %s): %w", sym, u, err)
+		return nil, false, fmt.Errorf("error decoding response: %w", err)
 	}
-	return quote, nil
+	return quote, false, nil
 }
 
 // createURL creates a URL for the given root URL and parameters.
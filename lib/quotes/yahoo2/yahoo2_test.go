@@ -0,0 +1,84 @@
+// Copyright 2021 Silvio Böhler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yahoo2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const response = `{"chart":{"result":[{"meta":{"exchangeTimezoneName":"UTC"},"timestamp":[1573084800],` +
+	`"indicators":{"quote":[{"volume":[100],"high":[2],"close":[1.5],"low":[1],"open":[1.2]}],"adjclose":[{"adjclose":[1.5]}]}}]}}`
+
+func TestFetchRetry(t *testing.T) {
+	tests := []struct {
+		desc         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			desc:         "success",
+			statuses:     []int{http.StatusOK},
+			wantRequests: 1,
+		},
+		{
+			desc:         "retry on 503",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantRequests: 3,
+		},
+		{
+			desc:         "give up after retries",
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			wantErr:      true,
+			wantRequests: 3,
+		},
+		{
+			desc:         "no retry on 404",
+			statuses:     []int{http.StatusNotFound, http.StatusOK},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := test.statuses[requests]
+				requests++
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(response))
+				}
+			}))
+			defer srv.Close()
+			client := Client{url: srv.URL, Retries: 2, Backoff: time.Millisecond}
+
+			got, err := client.Fetch("GOOG", time.Date(2019, 11, 7, 0, 0, 0, 0, time.UTC), time.Date(2019, 11, 8, 0, 0, 0, 0, time.UTC))
+
+			if test.wantErr && err == nil {
+				t.Errorf("client.Fetch() returned no error, want an error")
+			}
+			if !test.wantErr && (err != nil || len(got) != 1) {
+				t.Errorf("client.Fetch() = %v, %v, want one quote", got, err)
+			}
+			if requests != test.wantRequests {
+				t.Errorf("client.Fetch() made %d requests, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
@@ -15,16 +15,20 @@
 package balance
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/sboehler/knut/lib/amounts"
+	"github.com/sboehler/knut/lib/common/compare"
 	"github.com/sboehler/knut/lib/common/date"
 	"github.com/sboehler/knut/lib/common/mapper"
 	"github.com/sboehler/knut/lib/common/regex"
 	"github.com/sboehler/knut/lib/common/table"
 	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/account"
 	"github.com/sboehler/knut/lib/model/commodity"
 	"github.com/shopspring/decimal"
+	"golang.org/x/exp/slices"
 )
 
 // Renderer renders a report.
@@ -33,15 +37,40 @@ type Renderer struct {
 	CommodityDetails   regex.Regexes
 	SortAlphabetically bool
 	Diff               bool
+	PeriodLabels       string
+
+	// ByCommodity groups the asset and liability accounts by commodity,
+	// with a total per commodity.
+	ByCommodity bool
+
+	// ShowZeroDiff renders an explicit zero in diff mode for periods
+	// without movements, once the row has seen its first movement.
+	ShowZeroDiff bool
+
+	// FoldBelow folds the commodity rows of an account whose valued amount
+	// stays below the threshold into a single row. It is only effective
+	// when the report is valued and commodity details are shown.
+	FoldBelow decimal.Decimal
+
+	// ShowRates appends the rates used to valuate each commodity at the
+	// end of each period. It is only effective when the report is valued.
+	ShowRates bool
 
 	drawCommsColumn bool
 	partition       date.Partition
+	other           *model.Commodity
+	report          *Report
 }
 
 // Render renders a report.
 func (rn *Renderer) Render(r *Report) *table.Table {
 	rn.drawCommsColumn = rn.Valuation == nil || len(rn.CommodityDetails) > 0
 	rn.partition = r.partition
+	rn.other = new(model.Commodity)
+	rn.report = r
+	if rn.ByCommodity {
+		return rn.renderByCommodity(r)
+	}
 	r.SetAccounts()
 	if rn.SortAlphabetically {
 		r.SortAlpha()
@@ -60,7 +89,7 @@ func (rn *Renderer) Render(r *Report) *table.Table {
 		header.AddText("Comm", table.Center)
 	}
 	for _, d := range rn.partition.EndDates() {
-		header.AddText(d.Format("2006-01-02"), table.Center)
+		header.AddText(date.Label(d, rn.PeriodLabels), table.Center)
 	}
 	tbl.AddSeparatorRow()
 
@@ -73,21 +102,170 @@ func (rn *Renderer) Render(r *Report) *table.Table {
 		rn.renderNode(tbl, 0, false, n)
 		tbl.AddEmptyRow()
 	}
-	rn.render(tbl, 0, "Total (A+L)", false, totalAL)
+	rn.render(tbl, 0, "Total (A+L)", nil, false, totalAL)
 	tbl.AddSeparatorRow()
 	for _, n := range r.EIE.Sorted {
 		rn.renderNode(tbl, 0, true, n)
 		tbl.AddEmptyRow()
 	}
-	rn.render(tbl, 0, "Total (E+I+E)", true, totalEIE)
+	rn.render(tbl, 0, "Total (E+I+E)", nil, true, totalEIE)
 	tbl.AddSeparatorRow()
 	totalAL.Plus(totalEIE)
-	rn.render(tbl, 0, "Delta", false, totalAL)
+	rn.render(tbl, 0, "Delta", nil, false, totalAL)
+	tbl.AddSeparatorRow()
+	rn.renderUnvalued(tbl, r)
+	rn.renderRates(tbl, r)
+
+	return tbl
+}
+
+func (rn *Renderer) renderByCommodity(r *Report) *table.Table {
+	rn.drawCommsColumn = false
+	tbl := table.New(1, rn.partition.Size())
+	tbl.AddSeparatorRow()
+	header := tbl.AddRow().AddText("Commodity", table.Center)
+	for _, d := range rn.partition.EndDates() {
+		header.AddText(date.Label(d, rn.PeriodLabels), table.Center)
+	}
 	tbl.AddSeparatorRow()
 
+	var nodes []*Node
+	r.AL.PostOrder(func(n *Node) {
+		if len(n.Value.Amounts) > 0 {
+			nodes = append(nodes, n)
+		}
+	})
+	compare.Sort(nodes, func(n1, n2 *Node) compare.Order {
+		return account.Compare(n1.Value.Account, n2.Value.Account)
+	})
+	totals, _ := r.Totals(amounts.KeyMapper{
+		Date:      mapper.Identity[time.Time],
+		Commodity: mapper.Identity[*model.Commodity],
+	}.Build())
+	byCommodity := amounts.KeyMapper{
+		Date:      mapper.Identity[time.Time],
+		Commodity: mapper.Identity[*model.Commodity],
+	}.Build()
+	for _, c := range totals.CommoditiesSorted() {
+		tbl.AddRow().AddText(c.Name(), table.Left).FillEmpty()
+		for _, n := range nodes {
+			vals := n.Value.Amounts.SumBy(func(k amounts.Key) bool { return k.Commodity == c }, byCommodity)
+			if len(vals) > 0 {
+				rn.render(tbl, 2, n.Value.Account.Name(), n.Value.Account, false, vals)
+			}
+		}
+		rn.render(tbl, 2, "Total", nil, false, totals.SumBy(func(k amounts.Key) bool { return k.Commodity == c }, byCommodity))
+		tbl.AddSeparatorRow()
+	}
+	rn.renderUnvalued(tbl, r)
+	rn.renderRates(tbl, r)
 	return tbl
 }
 
+// renderUnvalued renders the positions in commodities without a rate in the
+// valuation commodity, in their native quantity, for the periods at whose
+// end the commodity has no rate.
+func (rn *Renderer) renderUnvalued(tbl *table.Table, r *Report) {
+	if rn.Valuation == nil || r.Unvalued == nil {
+		return
+	}
+	type position struct {
+		account   *model.Account
+		commodity *model.Commodity
+	}
+	var positions []position
+	flows := make(map[position]map[time.Time]decimal.Decimal)
+	for k, v := range r.Unvalued {
+		if k.Account == nil {
+			continue
+		}
+		pos := position{k.Account, k.Commodity}
+		if _, ok := flows[pos]; !ok {
+			positions = append(positions, pos)
+			flows[pos] = make(map[time.Time]decimal.Decimal)
+		}
+		flows[pos][k.Date] = flows[pos][k.Date].Add(v)
+	}
+	compare.Sort(positions, func(p1, p2 position) compare.Order {
+		if o := account.Compare(p1.account, p2.account); o != compare.Equal {
+			return o
+		}
+		return commodity.Compare(p1.commodity, p2.commodity)
+	})
+	var header bool
+	for _, pos := range positions {
+		var (
+			cells []decimal.Decimal
+			shown []bool
+			found bool
+			total decimal.Decimal
+		)
+		for _, d := range rn.partition.EndDates() {
+			v := flows[pos][d]
+			if !rn.Diff {
+				total = total.Add(v)
+				v = total
+			}
+			_, valued := r.Rates[d][pos.commodity]
+			cells, shown = append(cells, v), append(shown, !valued && !v.IsZero())
+			found = found || !valued && !v.IsZero()
+		}
+		if !found {
+			continue
+		}
+		if !header {
+			tbl.AddRow().AddText("Unvalued", table.Left).FillEmpty()
+			header = true
+		}
+		row := tbl.AddRow()
+		if rn.drawCommsColumn {
+			row.AddIndented(pos.account.Name(), 2).AddText(pos.commodity.Name(), table.Left)
+		} else {
+			row.AddIndented(fmt.Sprintf("%s (%s)", pos.account.Name(), pos.commodity.Name()), 2)
+		}
+		for i, v := range cells {
+			if shown[i] {
+				row.AddDecimal(v)
+			} else {
+				row.AddEmpty()
+			}
+		}
+	}
+	if header {
+		tbl.AddSeparatorRow()
+	}
+}
+
+// renderRates renders the rate of each commodity in the report, in units of
+// the valuation commodity, at the end of each period.
+func (rn *Renderer) renderRates(tbl *table.Table, r *Report) {
+	if !rn.ShowRates || rn.Valuation == nil {
+		return
+	}
+	al, eie := r.Totals(amounts.KeyMapper{
+		Commodity: mapper.Identity[*model.Commodity],
+	}.Build())
+	al.Plus(eie)
+	tbl.AddRow().AddText("Rates", table.Left).FillEmpty()
+	for _, c := range al.CommoditiesSorted() {
+		if c == rn.Valuation {
+			continue
+		}
+		row := tbl.AddRow().AddIndented(c.Name(), 2)
+		if rn.drawCommsColumn {
+			row.AddText(rn.Valuation.Name(), table.Left)
+		}
+		for _, d := range rn.partition.EndDates() {
+			if rate, ok := r.Rates[d][c]; ok {
+				row.AddText(rate.String(), table.Right)
+			} else {
+				row.AddEmpty()
+			}
+		}
+	}
+	tbl.AddSeparatorRow()
+}
+
 func (rn *Renderer) renderNode(t *table.Table, indent int, neg bool, n *Node) {
 	var vals amounts.Amounts
 	if n.Value.Account != nil {
@@ -96,21 +274,74 @@ func (rn *Renderer) renderNode(t *table.Table, indent int, neg bool, n *Node) {
 			Date:      mapper.Identity[time.Time],
 			Commodity: commodity.IdentityIf(showCommodities),
 		}.Build())
+		if showCommodities && rn.Valuation != nil && rn.FoldBelow.IsPositive() {
+			vals = rn.fold(vals)
+		}
 	}
 	if n.Segment != "" {
-		rn.render(t, indent, n.Segment, neg, vals)
+		rn.render(t, indent, n.Segment, n.Value.Account, neg, vals)
 	}
 	for _, ch := range n.Sorted {
 		rn.renderNode(t, indent+2, neg, ch)
 	}
 }
 
-func (rn *Renderer) render(t *table.Table, indent int, name string, neg bool, vals amounts.Amounts) {
+// exists returns whether the account is open during the period. For rows
+// without an account, or accounts whose opening is unknown, it falls back to
+// whether a movement has been seen.
+func (rn *Renderer) exists(acc *model.Account, p date.Period, moved bool) bool {
+	if acc == nil {
+		return moved
+	}
+	if open, known := rn.report.IsOpen(acc, p); known {
+		return open
+	}
+	return moved
+}
+
+// fold merges the commodities whose displayed amount never reaches the
+// threshold into a single row.
+func (rn *Renderer) fold(vals amounts.Amounts) amounts.Amounts {
+	small := make(map[*model.Commodity]bool)
+	for _, c := range vals.CommoditiesSorted() {
+		var total, peak decimal.Decimal
+		for _, d := range rn.partition.EndDates() {
+			v := vals[amounts.DateCommodityKey(d, c)]
+			if !rn.Diff {
+				total = total.Add(v)
+				v = total
+			}
+			if v.Abs().GreaterThan(peak) {
+				peak = v.Abs()
+			}
+		}
+		if peak.LessThan(rn.FoldBelow) {
+			small[c] = true
+		}
+	}
+	if len(small) < 2 {
+		return vals
+	}
+	return vals.SumBy(nil, func(k amounts.Key) amounts.Key {
+		if small[k.Commodity] {
+			k.Commodity = rn.other
+		}
+		return k
+	})
+}
+
+// render renders the rows of the given amounts. The account, if not nil,
+// determines in which periods the row exists for ShowZeroDiff.
+func (rn *Renderer) render(t *table.Table, indent int, name string, acc *model.Account, neg bool, vals amounts.Amounts) {
 	if len(vals) == 0 {
 		t.AddRow().AddIndented(name, indent).FillEmpty()
 		return
 	}
-	for i, commodity := range vals.CommoditiesSorted() {
+	commodities := vals.CommoditiesSorted()
+	if i := slices.Index(commodities, rn.other); i >= 0 {
+		commodities = append(slices.Delete(commodities, i, i+1), rn.other)
+	}
+	for i, commodity := range commodities {
 		row := t.AddRow()
 		if i == 0 {
 			row.AddIndented(name, indent)
@@ -118,7 +349,9 @@ func (rn *Renderer) render(t *table.Table, indent int, name string, neg bool, va
 			row.AddEmpty()
 		}
 		if rn.drawCommsColumn {
-			if commodity != nil {
+			if commodity == rn.other {
+				row.AddText("other", table.Left)
+			} else if commodity != nil {
 				row.AddText(commodity.Name(), table.Left)
 			} else if rn.Valuation != nil {
 				row.AddText(rn.Valuation.Name(), table.Left)
@@ -126,9 +359,14 @@ func (rn *Renderer) render(t *table.Table, indent int, name string, neg bool, va
 				row.AddEmpty()
 			}
 		}
-		var total decimal.Decimal
-		for _, date := range rn.partition.EndDates() {
-			v := vals[amounts.DateCommodityKey(date, commodity)]
+		var (
+			total  decimal.Decimal
+			moved  bool
+			starts = rn.partition.StartDates()
+		)
+		for i, end := range rn.partition.EndDates() {
+			v, ok := vals[amounts.DateCommodityKey(end, commodity)]
+			moved = moved || ok
 			if !rn.Diff {
 				total = total.Add(v)
 				v = total
@@ -136,6 +374,10 @@ func (rn *Renderer) render(t *table.Table, indent int, name string, neg bool, va
 			if neg {
 				v = v.Neg()
 			}
+			if rn.Diff && rn.ShowZeroDiff && v.IsZero() && rn.exists(acc, date.Period{Start: starts[i], End: end}, moved) {
+				row.AddZero()
+				continue
+			}
 			row.AddDecimal(v)
 		}
 	}
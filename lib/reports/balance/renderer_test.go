@@ -0,0 +1,67 @@
+package balance
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+
+	"github.com/sboehler/knut/lib/amounts"
+	"github.com/sboehler/knut/lib/common/date"
+	"github.com/sboehler/knut/lib/model"
+	"github.com/sboehler/knut/lib/model/registry"
+)
+
+func TestFold(t *testing.T) {
+	var (
+		reg  = registry.New()
+		chf  = reg.Commodities().MustGet("CHF")
+		usd  = reg.Commodities().MustGet("USD")
+		eur  = reg.Commodities().MustGet("EUR")
+		d    = date.Date(2020, 1, 31)
+		part = date.NewPartition(date.Period{Start: date.Date(2020, 1, 1), End: d}, date.Once, 0)
+	)
+	tests := []struct {
+		desc  string
+		input map[*model.Commodity]int64
+		want  map[string]int64
+	}{
+		{
+			desc:  "keeps a single small commodity among large ones",
+			input: map[*model.Commodity]int64{chf: 1000, usd: 2000, eur: 5},
+			want:  map[string]int64{"CHF": 1000, "USD": 2000, "EUR": 5},
+		},
+		{
+			desc:  "folds several small commodities",
+			input: map[*model.Commodity]int64{chf: 1000, usd: 3, eur: 5},
+			want:  map[string]int64{"CHF": 1000, "other": 8},
+		},
+		{
+			desc:  "keeps large commodities",
+			input: map[*model.Commodity]int64{chf: 1000, usd: 2000},
+			want:  map[string]int64{"CHF": 1000, "USD": 2000},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			rn := Renderer{FoldBelow: decimal.NewFromInt(10), partition: part, other: new(model.Commodity)}
+			vals := make(amounts.Amounts)
+			for c, q := range test.input {
+				vals.Add(amounts.DateCommodityKey(d, c), decimal.NewFromInt(q))
+			}
+
+			got := make(map[string]int64)
+			for k, v := range rn.fold(vals) {
+				name := "other"
+				if k.Commodity != rn.other {
+					name = k.Commodity.Name()
+				}
+				got[name] = v.IntPart()
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("fold(): unexpected diff (-want/+got):\n%s", diff)
+			}
+		})
+	}
+}
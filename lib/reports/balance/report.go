@@ -1,13 +1,18 @@
 package balance
 
 import (
+	"strings"
+	"time"
+
 	"github.com/sboehler/knut/lib/amounts"
 	"github.com/sboehler/knut/lib/common/compare"
 	"github.com/sboehler/knut/lib/common/date"
 	"github.com/sboehler/knut/lib/common/mapper"
 	"github.com/sboehler/knut/lib/common/multimap"
+	"github.com/sboehler/knut/lib/journal"
 	"github.com/sboehler/knut/lib/model"
 	"github.com/sboehler/knut/lib/model/account"
+	"github.com/sboehler/knut/lib/model/price"
 	"github.com/shopspring/decimal"
 )
 
@@ -15,6 +20,26 @@ type Report struct {
 	Registry  *model.Registry
 	AL, EIE   *multimap.Node[Value]
 	partition date.Partition
+
+	// Rates holds the normalized prices used at the end of each period.
+	Rates map[time.Time]price.NormalizedPrices
+
+	// lifetimes holds the period in which each account is open, with a
+	// zero end for accounts which are not closed.
+	lifetimes map[*model.Account]date.Period
+
+	// Unvalued, if not nil, holds the native quantities of the asset and
+	// liability positions. The positions in commodities without a rate at
+	// the end of a period are rendered as unvalued.
+	Unvalued Unvalued
+}
+
+// Unvalued collects native quantities.
+type Unvalued amounts.Amounts
+
+// Insert implements journal.Collection.
+func (u Unvalued) Insert(k amounts.Key, v decimal.Decimal) {
+	amounts.Amounts(u).Add(k, v)
 }
 
 type Value struct {
@@ -51,6 +76,46 @@ func (r *Report) Insert(k amounts.Key, v decimal.Decimal) {
 	n.Value.Amounts.Add(k, v)
 }
 
+// TrackAccounts records the dates at which accounts are opened and closed.
+func (r *Report) TrackAccounts() *journal.Processor {
+	r.lifetimes = make(map[*model.Account]date.Period)
+	return &journal.Processor{
+		Open: func(o *model.Open) error {
+			r.lifetimes[o.Account] = date.Period{Start: o.Date}
+			return nil
+		},
+		Close: func(c *model.Close) error {
+			p := r.lifetimes[c.Account]
+			p.End = c.Date
+			r.lifetimes[c.Account] = p
+			return nil
+		},
+	}
+}
+
+// IsOpen returns whether the account or one of its descendants is open
+// during part of the period. known is false if none of them was tracked.
+func (r *Report) IsOpen(a *model.Account, p date.Period) (open, known bool) {
+	for acc, lt := range r.lifetimes {
+		if acc != a && !strings.HasPrefix(acc.Name(), a.Name()+":") {
+			continue
+		}
+		known = true
+		if !lt.Start.After(p.End) && (lt.End.IsZero() || !lt.End.Before(p.Start)) {
+			return true, true
+		}
+	}
+	return false, known
+}
+
+// AddRates records the normalized prices used at the given date.
+func (r *Report) AddRates(d time.Time, np price.NormalizedPrices) {
+	if r.Rates == nil {
+		r.Rates = make(map[time.Time]price.NormalizedPrices)
+	}
+	r.Rates[d] = np
+}
+
 func (r *Report) SortAlpha() {
 	f := func(n1, n2 *Node) compare.Order {
 		if n1.Value.Account.Level() == 1 && n2.Value.Account.Level() == 1 {
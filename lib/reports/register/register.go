@@ -47,6 +47,10 @@ type Renderer struct {
 	ShowSource         bool
 	ShowDescriptions   bool
 	SortAlphabetically bool
+
+	// Collate orders the rows of a date by source account first,
+	// so that the postings of each account are shown together.
+	Collate bool
 }
 
 func (rn *Renderer) Render(r *Report) *table.Table {
@@ -91,6 +95,9 @@ func (rn *Renderer) renderNode(tbl *table.Table, n *Node) {
 	} else {
 		cmp = compareAccount
 	}
+	if rn.Collate {
+		cmp = compareSource(cmp)
+	}
 	idx := n.Amounts.Index(cmp)
 	for i, k := range idx {
 		row := tbl.AddRow()
@@ -128,3 +135,12 @@ func compareAccountAndCommodities(k1, k2 amounts.Key) compare.Order {
 	}
 	return commodity.Compare(k1.Commodity, k2.Commodity)
 }
+
+func compareSource(cmp compare.Compare[amounts.Key]) compare.Compare[amounts.Key] {
+	return func(k1, k2 amounts.Key) compare.Order {
+		if c := account.Compare(k1.Account, k2.Account); c != compare.Equal {
+			return c
+		}
+		return cmp(k1, k2)
+	}
+}
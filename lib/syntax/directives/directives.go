@@ -53,6 +53,9 @@ type Booking struct {
 	Credit, Debit Account
 	Quantity      Decimal
 	Commodity     Commodity
+
+	// Comment is an optional comment following the booking on the same line.
+	Comment Range
 }
 
 type Performance struct {
@@ -125,11 +128,29 @@ type Price struct {
 	Price             Decimal
 }
 
+type Split struct {
+	Range
+	Date                   Date
+	Commodity              Commodity
+	Numerator, Denominator Decimal
+}
+
 type Include struct {
 	Range
 	IncludePath QuotedString
 }
 
+type Alias struct {
+	Range
+	Name    Range
+	Account Account
+}
+
+type Option struct {
+	Range
+	Key, Value QuotedString
+}
+
 type Range struct {
 	Start, End int
 	Path, Text string
@@ -224,6 +245,10 @@ type Error struct {
 	Wrapped error
 }
 
+func (e Error) Unwrap() error {
+	return e.Wrapped
+}
+
 func (e Error) Error() string {
 	var s strings.Builder
 	if e.Wrapped != nil {
@@ -80,6 +80,14 @@ func (p *Parser) parseDirective() (directives.Directive, error) {
 		if dir.Directive, err = p.parseInclude(); err != nil {
 			return directives.SetRange(&dir, s.Range()), s.Annotate(err)
 		}
+	} else if p.HasPrefix("alias") {
+		if dir.Directive, err = p.parseAlias(); err != nil {
+			return directives.SetRange(&dir, s.Range()), s.Annotate(err)
+		}
+	} else if p.HasPrefix("option") {
+		if dir.Directive, err = p.parseOption(); err != nil {
+			return directives.SetRange(&dir, s.Range()), s.Annotate(err)
+		}
 	} else {
 		date, err := p.parseDate()
 		if err != nil {
@@ -93,7 +101,7 @@ func (p *Parser) parseDirective() (directives.Directive, error) {
 				return directives.SetRange(&dir, s.Range()), s.Annotate(err)
 			}
 		} else {
-			r, err := p.ReadAlternative([]string{"open", "close", "balance", "price"})
+			r, err := p.ReadAlternative([]string{"open", "close", "balance", "price", "split"})
 			if err != nil {
 				return directives.SetRange(&dir, s.Range()), s.Annotate(err)
 			}
@@ -117,6 +125,10 @@ func (p *Parser) parseDirective() (directives.Directive, error) {
 				if dir.Directive, err = p.parsePrice(s, date); err != nil {
 					return directives.SetRange(&dir, s.Range()), s.Annotate(err)
 				}
+			case "split":
+				if dir.Directive, err = p.parseSplit(s, date); err != nil {
+					return directives.SetRange(&dir, s.Range()), s.Annotate(err)
+				}
 			}
 		}
 	}
@@ -141,6 +153,60 @@ func (p *Parser) parseInclude() (directives.Include, error) {
 	return directives.SetRange(&include, s.Range()), nil
 }
 
+func (p *Parser) parseAlias() (directives.Alias, error) {
+	s := p.Scope("parsing `alias` statement")
+	var (
+		alias = directives.Alias{}
+		err   error
+	)
+	if _, err := p.ReadString("alias"); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.readWhitespace1(); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	if alias.Name, err = p.ReadWhile1("a letter or a digit", isAlphanumeric); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.readWhitespace1(); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.ReadCharacter('='); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.readWhitespace1(); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	if alias.Account, err = p.parseAccount(); err != nil {
+		return directives.SetRange(&alias, s.Range()), s.Annotate(err)
+	}
+	return directives.SetRange(&alias, s.Range()), nil
+}
+
+func (p *Parser) parseOption() (directives.Option, error) {
+	s := p.Scope("parsing `option` statement")
+	var (
+		option = directives.Option{}
+		err    error
+	)
+	if _, err := p.ReadString("option"); err != nil {
+		return directives.SetRange(&option, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.readWhitespace1(); err != nil {
+		return directives.SetRange(&option, s.Range()), s.Annotate(err)
+	}
+	if option.Key, err = p.parseQuotedString(); err != nil {
+		return directives.SetRange(&option, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.readWhitespace1(); err != nil {
+		return directives.SetRange(&option, s.Range()), s.Annotate(err)
+	}
+	if option.Value, err = p.parseQuotedString(); err != nil {
+		return directives.SetRange(&option, s.Range()), s.Annotate(err)
+	}
+	return directives.SetRange(&option, s.Range()), nil
+}
+
 func (p *Parser) parseOpen(s scanner.Scope, date directives.Date) (directives.Open, error) {
 	s.UpdateDesc("parsing `open` directive")
 	var (
@@ -246,6 +312,30 @@ func (p *Parser) parsePrice(s scanner.Scope, date directives.Date) (directives.P
 	return directives.SetRange(&price, s.Range()), err
 }
 
+func (p *Parser) parseSplit(s scanner.Scope, date directives.Date) (directives.Split, error) {
+	s.UpdateDesc("parsing `split` directive")
+	var (
+		split = directives.Split{Date: date}
+		err   error
+	)
+	if split.Commodity, err = p.parseCommodity(); err != nil {
+		return directives.SetRange(&split, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.readWhitespace1(); err != nil {
+		return directives.SetRange(&split, s.Range()), s.Annotate(err)
+	}
+	if split.Numerator, err = p.parseDecimal(); err != nil {
+		return directives.SetRange(&split, s.Range()), s.Annotate(err)
+	}
+	if _, err := p.ReadCharacter(':'); err != nil {
+		return directives.SetRange(&split, s.Range()), s.Annotate(err)
+	}
+	if split.Denominator, err = p.parseDecimal(); err != nil {
+		return directives.SetRange(&split, s.Range()), s.Annotate(err)
+	}
+	return directives.SetRange(&split, s.Range()), nil
+}
+
 func (p *Parser) parseCommodity() (directives.Commodity, error) {
 	var (
 		commodity directives.Commodity
@@ -394,6 +484,9 @@ func (p *Parser) parseTransaction(s scanner.Scope, date directives.Date, addons
 	}
 	for {
 		b, err := p.parseBooking()
+		if err == nil {
+			b.Comment, err = p.readTrailingComment()
+		}
 		trx.Bookings = append(trx.Bookings, b)
 		if err != nil {
 			return directives.SetRange(&trx, s.Range()), s.Annotate(err)
@@ -543,6 +636,22 @@ func (p *Parser) readWhitespace1() (directives.Range, error) {
 	return p.ReadWhile(isWhitespace)
 }
 
+// readTrailingComment reads an optional comment following a booking
+// on the same line, and returns its range.
+func (p *Parser) readTrailingComment() (directives.Range, error) {
+	if p.Current() != ' ' && p.Current() != '\t' {
+		return directives.Range{}, nil
+	}
+	s := p.Scope("reading trailing comment")
+	if _, err := p.ReadWhile(isWhitespace); err != nil {
+		return directives.Range{}, s.Annotate(err)
+	}
+	if p.Current() != '*' && p.Current() != '#' && p.Current() != '/' {
+		return directives.Range{}, nil
+	}
+	return p.readComment()
+}
+
 func (p *Parser) readRestOfWhitespaceLine() (directives.Range, error) {
 	s := p.Scope("reading the rest of the line")
 	if _, err := p.ReadWhile(isWhitespace); err != nil {
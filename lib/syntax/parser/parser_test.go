@@ -973,6 +973,104 @@ func TestParseInclude(t *testing.T) {
 	}.run(t)
 }
 
+func TestParseAlias(t *testing.T) {
+	parserTest[directives.Alias]{
+		tests: []testcase[directives.Alias]{
+			{
+				text: `alias co = Assets:Checking`,
+				want: func(t string) directives.Alias {
+					return directives.Alias{
+						Range: Range{End: 26, Text: t},
+						Name:  Range{Start: 6, End: 8, Text: t},
+						Account: directives.Account{
+							Range: Range{Start: 11, End: 26, Text: t},
+						},
+					}
+				},
+			},
+			{
+				text: `alias co Assets:Checking`,
+				want: func(s string) directives.Alias {
+					return directives.Alias{
+						Range: Range{End: 9, Text: s},
+						Name:  Range{Start: 6, End: 8, Text: s},
+					}
+				},
+				err: func(s string) error {
+					return directives.Error{
+						Message: "while parsing `alias` statement",
+						Range:   Range{End: 9, Text: s},
+						Wrapped: directives.Error{
+							Range:   directives.Range{Start: 9, End: 9, Text: s},
+							Message: "unexpected character `A`, want `=`",
+						},
+					}
+				},
+			},
+		},
+		desc: "p.parseAlias()",
+		fn: func(p *Parser) (directives.Alias, error) {
+			return p.parseAlias()
+		},
+	}.run(t)
+}
+
+func TestParseOption(t *testing.T) {
+	parserTest[directives.Option]{
+		tests: []testcase[directives.Option]{
+			{
+				text: `option "valuation" "CHF"`,
+				want: func(t string) directives.Option {
+					return directives.Option{
+						Range: Range{End: 24, Text: t},
+						Key: directives.QuotedString{
+							Range:   Range{Start: 7, End: 18, Text: t},
+							Content: Range{Start: 8, End: 17, Text: t},
+						},
+						Value: directives.QuotedString{
+							Range:   Range{Start: 19, End: 24, Text: t},
+							Content: Range{Start: 20, End: 23, Text: t},
+						},
+					}
+				},
+			},
+			{
+				text: `option "valuation" CHF`,
+				want: func(s string) directives.Option {
+					return directives.Option{
+						Range: Range{End: 19, Text: s},
+						Key: directives.QuotedString{
+							Range:   Range{Start: 7, End: 18, Text: s},
+							Content: Range{Start: 8, End: 17, Text: s},
+						},
+						Value: directives.QuotedString{
+							Range: Range{Start: 19, End: 19, Text: s},
+						},
+					}
+				},
+				err: func(s string) error {
+					return directives.Error{
+						Message: "while parsing `option` statement",
+						Range:   Range{End: 19, Text: s},
+						Wrapped: directives.Error{
+							Message: "while parsing quoted string",
+							Range:   Range{Start: 19, End: 19, Text: s},
+							Wrapped: directives.Error{
+								Range:   directives.Range{Start: 19, End: 19, Text: s},
+								Message: "unexpected character `C`, want `\"`",
+							},
+						},
+					}
+				},
+			},
+		},
+		desc: "p.parseOption()",
+		fn: func(p *Parser) (directives.Option, error) {
+			return p.parseOption()
+		},
+	}.run(t)
+}
+
 func TestParseQuotedString(t *testing.T) {
 	parserTest[directives.QuotedString]{
 		desc: "p.parseQuotedString()",
@@ -1059,6 +1157,28 @@ func TestParseTransaction(t *testing.T) {
 					}
 				},
 			},
+			{
+				text: "\"foo\"\n" + "A B 1 CHF  # TODO\n", // 6 + 18
+				want: func(t string) directives.Transaction {
+					return directives.Transaction{
+						Range: Range{End: 24, Text: t},
+						Description: directives.QuotedString{
+							Range:   Range{End: 5, Text: t},
+							Content: Range{Start: 1, End: 4, Text: t},
+						},
+						Bookings: []directives.Booking{
+							{
+								Range:     Range{Start: 6, End: 15, Text: t},
+								Credit:    directives.Account{Range: Range{Start: 6, End: 7, Text: t}},
+								Debit:     directives.Account{Range: Range{Start: 8, End: 9, Text: t}},
+								Quantity:  directives.Decimal{Range: Range{Start: 10, End: 11, Text: t}},
+								Commodity: directives.Commodity{Range: Range{Start: 12, End: 15, Text: t}},
+								Comment:   Range{Start: 17, End: 23, Text: t},
+							},
+						},
+					}
+				},
+			},
 			{
 				text: "\"foo\"\n" + "A B 1 CHF\n" + "B A 1 CHF\n", // 6 + 10 + 10
 				want: func(t string) directives.Transaction {
@@ -1371,6 +1491,21 @@ func TestParseDirective(t *testing.T) {
 					}
 				},
 			},
+			{
+				text: "2024-06-01 split AAPL 4:1",
+				want: func(s string) directives.Directive {
+					return directives.Directive{
+						Range: Range{End: 25, Text: s},
+						Directive: directives.Split{
+							Range:       Range{End: 25, Text: s},
+							Date:        directives.Date{Range: directives.Range{End: 10, Text: s}},
+							Commodity:   directives.Commodity{Range: directives.Range{Start: 17, End: 21, Text: s}},
+							Numerator:   directives.Decimal{Range: directives.Range{Start: 22, End: 23, Text: s}},
+							Denominator: directives.Decimal{Range: directives.Range{Start: 24, End: 25, Text: s}},
+						},
+					}
+				},
+			},
 		},
 		desc: "p.parseDirective()",
 		fn: func(p *Parser) (directives.Directive, error) {
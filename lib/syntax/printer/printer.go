@@ -59,8 +59,14 @@ func (p *Printer) printDirective(directive directives.Directive) error {
 		return p.printAssertion(d)
 	case directives.Include:
 		return p.printInclude(d)
+	case directives.Alias:
+		return p.printAlias(d)
+	case directives.Option:
+		return p.printOption(d)
 	case directives.Price:
 		return p.printPrice(d)
+	case directives.Split:
+		return p.printSplit(d)
 	}
 	return fmt.Errorf("unknown directive: %v", directive)
 }
@@ -103,8 +109,14 @@ func (p *Printer) printAccrual(a directives.Accrual) error {
 }
 
 func (p *Printer) printPosting(t directives.Booking) error {
-	_, err := fmt.Fprintf(p, "%-*s %-*s %10s %s", p.padding, t.Credit.Extract(), p.padding, t.Debit.Extract(), t.Quantity.Extract(), t.Commodity.Extract())
-	return err
+	if _, err := fmt.Fprintf(p, "%-*s %-*s %10s %s", p.padding, t.Credit.Extract(), p.padding, t.Debit.Extract(), t.Quantity.Extract(), t.Commodity.Extract()); err != nil {
+		return err
+	}
+	if !t.Comment.Empty() {
+		_, err := fmt.Fprintf(p, " %s", strings.TrimRight(t.Comment.Extract(), " \t\r"))
+		return err
+	}
+	return nil
 }
 
 func (p *Printer) printOpen(o directives.Open) error {
@@ -122,11 +134,26 @@ func (p *Printer) printPrice(pr directives.Price) error {
 	return err
 }
 
+func (p *Printer) printSplit(s directives.Split) error {
+	_, err := fmt.Fprintf(p, "%s split %s %s:%s", s.Date.Extract(), s.Commodity.Extract(), s.Numerator.Extract(), s.Denominator.Extract())
+	return err
+}
+
 func (p *Printer) printInclude(i directives.Include) error {
 	_, err := fmt.Fprintf(p, "include \"%s\"", i.IncludePath.Content.Extract())
 	return err
 }
 
+func (p *Printer) printAlias(a directives.Alias) error {
+	_, err := fmt.Fprintf(p, "alias %s = %s", a.Name.Extract(), a.Account.Extract())
+	return err
+}
+
+func (p *Printer) printOption(o directives.Option) error {
+	_, err := fmt.Fprintf(p, "option \"%s\" \"%s\"", o.Key.Content.Extract(), o.Value.Content.Extract())
+	return err
+}
+
 func (p *Printer) printAssertion(a directives.Assertion) error {
 	if _, err := fmt.Fprintf(p, "%s balance", a.Date.Extract()); err != nil {
 		return err
@@ -27,6 +27,18 @@ func TestPrintFile(t *testing.T) {
 				"",
 			),
 		},
+		{
+			desc: "print transaction with comment",
+			text: lines(
+				`2022-03-03    "Hello, world"`,
+				`A:B:C       C:B:ASDF   400 CHF   # TODO   `,
+			),
+			want: lines(
+				`2022-03-03 "Hello, world"`,
+				"A:B:C C:B:ASDF        400 CHF # TODO",
+				"",
+			),
+		},
 		{
 			desc: "print transactions",
 			text: lines(
@@ -89,6 +101,33 @@ func TestPrintFile(t *testing.T) {
 				`include "foo3"`,
 			),
 		},
+		{
+			desc: "print split",
+			text: lines(
+				`2024-06-01   split  AAPL   4:1`,
+			),
+			want: lines(
+				`2024-06-01 split AAPL 4:1`,
+			),
+		},
+		{
+			desc: "print alias",
+			text: lines(
+				`alias   co    =  Assets:Checking   `,
+			),
+			want: lines(
+				`alias co = Assets:Checking`,
+			),
+		},
+		{
+			desc: "print option",
+			text: lines(
+				`option  "valuation"    "CHF"  `,
+			),
+			want: lines(
+				`option "valuation" "CHF"`,
+			),
+		},
 		{
 			desc: "print open",
 			text: lines(
@@ -117,6 +117,11 @@ func (s *Scanner) Advance() error {
 	return nil
 }
 
+// HasPrefix returns whether the remaining input starts with the given string.
+func (s *Scanner) HasPrefix(str string) bool {
+	return strings.HasPrefix(s.text[s.offset:], str)
+}
+
 func (s *Scanner) Scope(desc string) Scope {
 	scope := Scope{
 		Desc:    desc,
@@ -51,8 +51,14 @@ type Balance = directives.Balance
 
 type Price = directives.Price
 
+type Split = directives.Split
+
 type Include = directives.Include
 
+type Alias = directives.Alias
+
+type Option = directives.Option
+
 type Range = directives.Range
 
 type Location = directives.Location
@@ -24,6 +24,7 @@ import (
 	_ "github.com/sboehler/knut/cmd/importer/cumulus"
 	_ "github.com/sboehler/knut/cmd/importer/interactivebrokers"
 	_ "github.com/sboehler/knut/cmd/importer/n26"
+	_ "github.com/sboehler/knut/cmd/importer/positions"
 	_ "github.com/sboehler/knut/cmd/importer/postfinance"
 	_ "github.com/sboehler/knut/cmd/importer/revolut"
 	_ "github.com/sboehler/knut/cmd/importer/revolut2"